@@ -0,0 +1,123 @@
+package mahjong
+
+import (
+	"errors"
+	"sort"
+)
+
+//打牌候補、Ukeireは切った後の向聴数と受け入れ
+type DiscardAdvice struct {
+	Tile
+	Ukeire
+	//聴牌ならロンした時の点数の期待値
+	Value float64
+}
+
+//向聴数の低い順、受け入れ枚数の多い順、期待値の高い順に並べる
+func (maj *Mahjong) AdviseDiscards(player *Player, value bool) ([]DiscardAdvice, error) {
+	if err := player.Phase.Check(RemoveTile); err != nil {
+		return nil, err
+	}
+	candidates := discardCandidates(player)
+	if len(candidates) == 0 {
+		return nil, errors.New("No tile to discard. ")
+	}
+	visible := maj.VisibleTiles(player)
+	advices := make([]DiscardAdvice, 0, len(candidates))
+	for _, candidate := range candidates {
+		rest := removeTile(player.Tiles, candidate)
+		advice := DiscardAdvice{Tile: candidate, Ukeire: CountUkeire(toTileTypes(rest), player.Melds(), visible)}
+		if value && advice.Shanten == 0 {
+			advice.Value = maj.expectedValue(player, rest, advice.Tiles)
+		}
+		advices = append(advices, advice)
+	}
+	sort.SliceStable(
+		advices, func(i, j int) bool {
+			a, b := advices[i], advices[j]
+			if a.Shanten != b.Shanten {
+				return a.Shanten < b.Shanten
+			}
+			if a.Total != b.Total {
+				return a.Total > b.Total
+			}
+			return a.Value > b.Value
+		},
+	)
+	return advices, nil
+}
+
+//同じ種類は一枚だけ、赤ドラはIdが小さいのでIdの大きい方を切る
+//立直後はツモ切りのみ
+func discardCandidates(player *Player) []Tile {
+	if !player.Riichi.First() {
+		return []Tile{player.LastDraw}
+	}
+	indexes := make(map[TileType]int)
+	candidates := make([]Tile, 0)
+	for _, tile := range SortTiles(append([]Tile(nil), player.Tiles...)) {
+		if i, ok := indexes[tile.TileType]; ok {
+			if tile.Id > candidates[i].Id {
+				candidates[i] = tile
+			}
+			continue
+		}
+		indexes[tile.TileType] = len(candidates)
+		candidates = append(candidates, tile)
+	}
+	return candidates
+}
+
+func removeTile(tiles []Tile, tile Tile) []Tile {
+	rest := make([]Tile, 0, len(tiles))
+	for i, t := range tiles {
+		if t == tile {
+			return append(rest, tiles[i+1:]...)
+		}
+		rest = append(rest, t)
+	}
+	return rest
+}
+
+//ルールの設定通りに和了形の点数を出せるルール
+type agariScorer interface {
+	score(agari Agari, menZen bool) ScoreSrc
+}
+
+//待ち牌の残り枚数で重み付けした平均、役がなければ0点
+//点数を出せないルールは見積もらない
+func (maj *Mahjong) expectedValue(player *Player, rest []Tile, waits []WaitingTile) float64 {
+	scorer, ok := maj.Rule.(agariScorer)
+	if !ok {
+		return 0
+	}
+	hypo := *player
+	hypo.Tiles = rest
+	menZen := hypo.Concealed()
+	total, weight := 0, 0
+	for _, wait := range waits {
+		if wait.Remaining == 0 {
+			continue
+		}
+		weight += wait.Remaining
+		agaris, err := maj.Rule.CanAgari(&hypo, Tile{TileType: wait.TileType})
+		if err != nil {
+			continue
+		}
+		best := ScoreSrc(0)
+		for _, agari := range agaris {
+			if score := scorer.score(agari, menZen); score > best {
+				best = score
+			}
+		}
+		point := best.ChildRon()
+		if player.IsParent(maj.Round) {
+			point = best.ParentRon()
+		}
+		total += point * wait.Remaining
+	}
+	if weight == 0 {
+		return 0
+	}
+	return float64(total) / float64(weight)
+}
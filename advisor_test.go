@@ -0,0 +1,73 @@
+package mahjong
+
+import "testing"
+
+func TestMahjong_AdviseDiscards(t *testing.T) {
+	maj, _ := newTestMahjong()
+	player := maj.Players.FindField(SouthField)
+	player.Tiles = toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Dots4, Dots5, Dots6, Bamboo5, Bamboo6, Bamboo7, Bamboo8, Bamboo8,
+			Dots3, Dots4, North,
+		},
+	)
+	if _, err := maj.AdviseDiscards(player, false); err == nil {
+		t.Error("AdviseDiscards() out of RemoveTile phase should fail")
+	}
+	player.Phase.Change(RemoveTile)
+
+	advices, err := maj.AdviseDiscards(player, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//同じ種類の4pは一度だけ
+	if len(advices) != 12 {
+		t.Errorf("len(AdviseDiscards()) = %v, want 12", len(advices))
+	}
+	best := advices[0]
+	if best.TileType != North || best.Shanten != 0 || best.Total != 7 || best.Value < 2000 {
+		t.Errorf("AdviseDiscards()[0] = %+v, want North with 2p5p wait", best)
+	}
+	for i := 1; i < len(advices); i++ {
+		if advices[i].Shanten < advices[i-1].Shanten {
+			t.Errorf("AdviseDiscards() is not sorted by shanten: %+v", advices)
+		}
+	}
+
+	//立直後はツモ切りのみ
+	player.Riichi = 1
+	player.LastDraw = player.Tiles[13]
+	if advices, _ := maj.AdviseDiscards(player, false); len(advices) != 1 || advices[0].Tile != player.LastDraw {
+		t.Errorf("AdviseDiscards() after riichi = %+v", advices)
+	}
+}
+
+//期待値はルールの切り上げ満貫に従う
+func TestMahjong_AdviseDiscards_Kiriage(t *testing.T) {
+	value := func(kiriage bool) float64 {
+		maj, _ := newTestMahjong()
+		options := DefaultRuleOptions
+		options.KiriageMangan = kiriage
+		maj.Rule.(*JapaneseHanChanRule).Options = &options
+		player := maj.Players.FindField(SouthField)
+		//5sなら平和断么三色の30符4飜
+		player.Tiles = toSampleTiles(
+			[]TileType{
+				Characters3, Characters4, Characters5, Dots3, Dots4, Dots5, Bamboo3, Bamboo4,
+				Characters6, Characters7, Characters8, Dots8, Dots8, North,
+			},
+		)
+		player.Phase.Change(RemoveTile)
+		advices, err := maj.AdviseDiscards(player, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if advices[0].TileType != North {
+			t.Fatalf("AdviseDiscards()[0] = %+v, want North", advices[0])
+		}
+		return advices[0].Value
+	}
+	if on, off := value(true), value(false); on <= off {
+		t.Errorf("Value with KiriageMangan = %v, without = %v, want larger", on, off)
+	}
+}
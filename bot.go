@@ -0,0 +1,282 @@
+package mahjong
+
+import (
+	"errors"
+	"sort"
+)
+
+//席の代わりに打つ、Viewだけを見て次の操作を決める
+//鳴きの受付中はEventDeclare、それ以外は手番の操作を返す
+type Bot interface {
+	Decide(view *View) Event
+}
+
+//受付中なら宣言していない人、そうでなければ手番の人に一つずつ選ばせる
+func (maj *Mahjong) StepBots(bots []Bot) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	if window := maj.ClaimWindow; window != nil {
+		for seat := EastField; int(seat) < len(bots); seat++ {
+			if _, declared := window.Claims[seat]; declared || !window.CanClaim(maj.Players.FindField(seat), ClaimPass) {
+				continue
+			}
+			return maj.botDecide(bots, seat)
+		}
+		return errors.New("No bot can declare. ")
+	}
+	return maj.botDecide(bots, maj.Players.Now().FieldWind)
+}
+
+//局が終わるまで打たせる
+func (maj *Mahjong) PlayBots(bots []Bot) error {
+	for !maj.Result.Done() {
+		if err := maj.StepBots(bots); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (maj *Mahjong) botDecide(bots []Bot, seat FieldWind) error {
+	if int(seat) >= len(bots) || bots[seat] == nil {
+		return errors.New("No bot at the seat. ")
+	}
+	view, err := maj.ViewFor(seat)
+	if err != nil {
+		return err
+	}
+	event := bots[seat].Decide(view)
+	event.Seats = []FieldWind{seat}
+	if maj.ClaimWindow != nil && event.Type != EventDeclare {
+		return WaitingForClaims{}
+	}
+	//ボットはツモる牌を知らないので直接ツモらせる
+	switch event.Type {
+	case EventDraw:
+		_, err = maj.Draw(maj.Players.FindField(seat))
+		return err
+	case EventDrawKan:
+		_, err = maj.DrawKan(maj.Players.FindField(seat))
+		return err
+	}
+	return maj.apply(event)
+}
+
+//どのボットでも同じ判断、和了・ツモ・抜きドラ・流局・嶺上牌
+func routineDecision(view *View) (Event, bool) {
+	actions := view.Actions
+	switch {
+	case view.Claims != nil:
+		for _, claim := range view.Claims {
+			if claim == ClaimRon {
+				return Event{Type: EventDeclare, ClaimType: ClaimRon}, true
+			}
+		}
+		return Event{Type: EventDeclare, ClaimType: ClaimPass}, true
+	case actions.Tsumo:
+		return Event{Type: EventTsumo}, true
+	case actions.Kita:
+		return Event{Type: EventKita, Tiles: actions.KitaOption[:1]}, true
+	case actions.DrawKan:
+		return Event{Type: EventDrawKan}, true
+	case view.Phase == AddTile && actions.Ryuukyoku:
+		return Event{Type: EventRyuukyoku}, true
+	case view.Phase == AddTile:
+		return Event{Type: EventDraw}, true
+	}
+	return Event{}, false
+}
+
+//ツモ牌がなければ(鳴いた後)手牌の最後
+func tsumogiriTile(view *View) Tile {
+	for _, tile := range view.Hand {
+		if tile == view.LastDraw {
+			return tile
+		}
+	}
+	return view.Hand[len(view.Hand)-1]
+}
+
+//ツモ切りだけ
+type TsumogiriBot struct{}
+
+func (TsumogiriBot) Decide(view *View) Event {
+	if event, ok := routineDecision(view); ok {
+		return event
+	}
+	return Event{Type: EventDahai, Tiles: []Tile{tsumogiriTile(view)}}
+}
+
+//向聴数と受け入れ枚数で選び、聴牌したら立直する、役牌はポンする
+type ShantenBot struct{}
+
+func (bot ShantenBot) Decide(view *View) Event {
+	if event, ok := bot.claim(view); ok {
+		return event
+	}
+	if event, ok := routineDecision(view); ok {
+		return event
+	}
+	if view.Seats[view.Seat].Riichi {
+		return Event{Type: EventDahai, Tiles: []Tile{tsumogiriTile(view)}}
+	}
+	advices := adviseView(view, discardCandidatesOf(view.Hand))
+	if view.Actions.Riichi {
+		riichi := adviseView(view, view.Actions.RiichiOption)
+		if len(riichi) != 0 {
+			return Event{Type: EventRiichi, Tiles: []Tile{riichi[0].Tile}}
+		}
+	}
+	return Event{Type: EventDahai, Tiles: []Tile{advices[0].Tile}}
+}
+
+//役牌で向聴数が下がるならポン
+func (ShantenBot) claim(view *View) (Event, bool) {
+	if view.Claims == nil || !view.Actions.Pon {
+		return Event{}, false
+	}
+	for _, claim := range view.Claims {
+		if claim == ClaimRon {
+			return Event{}, false
+		}
+	}
+	pair := view.Actions.PonOption[0]
+	if !isYakuhai(pair[0].TileType, view) {
+		return Event{}, false
+	}
+	melds := len(view.Seats[view.Seat].Melds)
+	rest := removeTile(removeTile(view.Hand, pair[0]), pair[1])
+	if NormalShanten(toTileTypes(rest), melds+1) >= NormalShanten(toTileTypes(view.Hand), melds) {
+		return Event{}, false
+	}
+	return Event{Type: EventDeclare, ClaimType: ClaimPon, Tiles: pair[:]}, true
+}
+
+func isYakuhai(tileType TileType, view *View) bool {
+	return tileType.IsDragon() || tileType == East+TileType(view.Round.FieldWind) ||
+		tileType == East+TileType(view.Seats[view.Seat].Wind)
+}
+
+//AdviseDiscardsと同じ並びをViewから作る
+func adviseView(view *View, candidates []Tile) []DiscardAdvice {
+	visible := view.VisibleTiles()
+	melds := len(view.Seats[view.Seat].Melds)
+	advices := make([]DiscardAdvice, 0, len(candidates))
+	for _, candidate := range candidates {
+		rest := removeTile(view.Hand, candidate)
+		advices = append(advices, DiscardAdvice{Tile: candidate, Ukeire: CountUkeire(toTileTypes(rest), melds, visible)})
+	}
+	sort.SliceStable(
+		advices, func(i, j int) bool {
+			a, b := advices[i], advices[j]
+			if a.Shanten != b.Shanten {
+				return a.Shanten < b.Shanten
+			}
+			return a.Total > b.Total
+		},
+	)
+	return advices
+}
+
+func discardCandidatesOf(tiles []Tile) []Tile {
+	return discardCandidates(&Player{Tiles: tiles})
+}
+
+//立直者がいて聴牌していなければ現物・筋・字牌の順にオリる
+type DefensiveBot struct {
+	ShantenBot
+}
+
+func (bot DefensiveBot) Decide(view *View) Event {
+	riichi := make([]SeatView, 0)
+	for _, seat := range view.Seats {
+		if seat.FieldWind != view.Seat && seat.Riichi {
+			riichi = append(riichi, seat)
+		}
+	}
+	if len(riichi) == 0 || view.Phase != RemoveTile || view.Claims != nil || view.Seats[view.Seat].Riichi {
+		return bot.ShantenBot.Decide(view)
+	}
+	if event, ok := routineDecision(view); ok {
+		return event
+	}
+	advices := adviseView(view, discardCandidatesOf(view.Hand))
+	if advices[0].Shanten == 0 {
+		return bot.ShantenBot.Decide(view)
+	}
+	visible := view.VisibleTiles()
+	best, bestDanger := advices[0].Tile, -1
+	for _, advice := range advices {
+		danger := 0
+		for _, seat := range riichi {
+			danger += Danger(advice.TileType, riichiDiscards(view, seat), visible)
+		}
+		if bestDanger < 0 || danger < bestDanger {
+			best, bestDanger = advice.Tile, danger
+		}
+	}
+	return Event{Type: EventDahai, Tiles: []Tile{best}}
+}
+
+//立直者の捨て牌に立直後に他家が切って見逃された牌を加える、立直後の見逃しはフリテンなので現物になる
+func riichiDiscards(view *View, riichi SeatView) []DiscardTile {
+	discards := append([]DiscardTile(nil), riichi.Discards...)
+	var declared *DiscardTile
+	for i := range riichi.Discards {
+		if riichi.Discards[i].Riichi {
+			declared = &riichi.Discards[i]
+			break
+		}
+	}
+	if declared == nil {
+		return discards
+	}
+	for _, seat := range view.Seats {
+		if seat.FieldWind == riichi.FieldWind {
+			continue
+		}
+		for _, discard := range seat.Discards {
+			//同じ巡なら立直者より後の席が後に切っている
+			if discard.Jun > declared.Jun || discard.Jun == declared.Jun && seat.FieldWind > riichi.FieldWind {
+				discards = append(discards, discard)
+			}
+		}
+	}
+	return discards
+}
+
+//立直者に対する危険度、0は現物
+func Danger(tileType TileType, discards []DiscardTile, visible [Red + 1]int) int {
+	discarded := make(map[TileType]bool)
+	for _, discard := range discards {
+		discarded[discard.TileType] = true
+	}
+	if discarded[tileType] {
+		return 0
+	}
+	if tileType.IsHonor() {
+		switch {
+		case visible[tileType] >= 3:
+			return 1
+		case visible[tileType] == 2:
+			return 2
+		}
+		return 4
+	}
+	n := tileType.Number()
+	low := n > 3 && discarded[tileType-3]
+	high := n < 7 && discarded[tileType+3]
+	//一二三と七八九は片側、四五六は両側が切られていれば筋
+	switch {
+	case n <= 3 && high, n >= 7 && low, low && high:
+		return 3
+	case low || high:
+		return 5
+	case n == 1 || n == 9:
+		return 5
+	case n == 2 || n == 8:
+		return 6
+	}
+	return 7
+}
@@ -0,0 +1,190 @@
+package mahjong
+
+import "testing"
+
+func TestMahjong_PlayBots(t *testing.T) {
+	tests := []struct {
+		name string
+		bots []Bot
+	}{
+		{"tsumogiri", []Bot{TsumogiriBot{}, TsumogiriBot{}, TsumogiriBot{}, TsumogiriBot{}}},
+		{"shanten", []Bot{ShantenBot{}, ShantenBot{}, ShantenBot{}, ShantenBot{}}},
+		{"mixed", []Bot{DefensiveBot{}, ShantenBot{}, TsumogiriBot{}, DefensiveBot{}}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj := newTestGame(t)
+				for hand := 0; hand < 4 && maj.GameOver == nil; hand++ {
+					if hand > 0 {
+						if err := maj.Restart(); err != nil {
+							t.Fatal(err)
+						}
+					}
+					if err := maj.PlayBots(tt.bots); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if _, ok := tt.bots[0].(TsumogiriBot); !ok {
+					return
+				}
+				maj.Players.Do(
+					func(player *Player) {
+						for _, discard := range player.Discards {
+							if !discard.TsumoGiri {
+								t.Errorf("discard %+v is not tsumogiri", discard)
+							}
+						}
+					},
+				)
+			},
+		)
+	}
+}
+
+func TestMahjong_StepBots(t *testing.T) {
+	maj := newTestGame(t)
+	if err := maj.StepBots(nil); err == nil {
+		t.Error("StepBots() without bots should fail")
+	}
+	//東家だけなら他家の番で止まる
+	bots := []Bot{TsumogiriBot{}}
+	if err := maj.StepBots(bots); err != nil {
+		t.Fatal(err)
+	}
+	for !maj.Result.Done() {
+		if err := maj.StepBots(bots); err != nil {
+			if maj.ClaimWindow == nil && maj.Players.Now().FieldWind == EastField {
+				t.Errorf("StepBots() = %v on the turn of east", err)
+			}
+			return
+		}
+	}
+	t.Error("StepBots() without the bot of the turn should fail")
+}
+
+func newBotView(t *testing.T, hand string) (*Mahjong, *View) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	tiles, err := ParseTiles(hand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.Tiles = tiles
+	parent.LastDraw = tiles[len(tiles)-1]
+	view, err := maj.ViewFor(parent.FieldWind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return maj, view
+}
+
+func TestShantenBot_Decide(t *testing.T) {
+	tests := []struct {
+		hand  string
+		want  EventType
+		tiles []TileType
+	}{
+		//孤立した字牌のどれかを切る
+		{"123m456p789s1134z5z", EventDahai, []TileType{West, North, White}},
+		{"123m456p789s11z23s5z", EventRiichi, []TileType{White}},
+		{"123m456p789s11z234s", EventTsumo, nil},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.hand, func(t *testing.T) {
+				_, view := newBotView(t, tt.hand)
+				event := ShantenBot{}.Decide(view)
+				if event.Type != tt.want {
+					t.Fatalf("Decide() = %+v, want %v", event, EventNames[tt.want])
+				}
+				if tt.tiles == nil {
+					return
+				}
+				for _, tileType := range tt.tiles {
+					if event.Tiles[0].TileType == tileType {
+						return
+					}
+				}
+				t.Errorf("Decide() discards %v, want one of %v", TilesName[event.Tiles[0].TileType], tt.tiles)
+			},
+		)
+	}
+}
+
+func TestDefensiveBot_Decide(t *testing.T) {
+	maj, _ := newBotView(t, "13m458p2789s1345z")
+	south := maj.Players.FindField(SouthField)
+	south.Riichi = 1
+	south.Discards = []DiscardTile{{Tile: Tile{TileType: Dots5, Id: 2}}, {Tile: Tile{TileType: Bamboo5, Id: 2}}}
+	view, err := maj.ViewFor(EastField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//向聴数は進まないが現物を切る
+	if event := (DefensiveBot{}).Decide(view); event.Type != EventDahai || event.Tiles[0].TileType != Dots5 {
+		t.Errorf("Decide() = %+v, want 5p", event)
+	}
+	if event := (ShantenBot{}).Decide(view); event.Tiles[0].TileType == Dots5 {
+		t.Errorf("ShantenBot should not fold, got %+v", event)
+	}
+}
+
+func TestDanger(t *testing.T) {
+	discards := []DiscardTile{{Tile: Tile{TileType: Dots4}}, {Tile: Tile{TileType: East}}}
+	var visible [Red + 1]int
+	visible[South] = 3
+	tests := []struct {
+		tileType TileType
+		want     int
+	}{
+		{Dots4, 0},
+		{East, 0},
+		{South, 1},
+		{West, 4},
+		{Dots1, 3},
+		{Dots7, 3},
+		{Dots5, 7},
+		{Dots2, 6},
+		{Dots9, 5},
+	}
+	for _, tt := range tests {
+		if got := Danger(tt.tileType, discards, visible); got != tt.want {
+			t.Errorf("Danger(%v) = %v, want %v", TilesName[tt.tileType], got, tt.want)
+		}
+	}
+}
+
+func TestRiichiDiscards(t *testing.T) {
+	view := &View{
+		Seats: []SeatView{
+			{FieldWind: EastField, Discards: []DiscardTile{{Tile: Tile{TileType: Dots1}, Jun: 1}, {Tile: Tile{TileType: Dots9}, Jun: 2}}},
+			{
+				FieldWind: SouthField, Riichi: true,
+				Discards: []DiscardTile{{Tile: Tile{TileType: East}, Jun: 1}, {Tile: Tile{TileType: North}, Jun: 2, Riichi: true}},
+			},
+			{FieldWind: WestField, Discards: []DiscardTile{{Tile: Tile{TileType: Dots3}, Jun: 1}, {Tile: Tile{TileType: Dots5}, Jun: 2}}},
+		},
+	}
+	var visible [Red + 1]int
+	discards := riichiDiscards(view, view.Seats[SouthField])
+	tests := []struct {
+		tileType TileType
+		want     int
+	}{
+		{North, 0},
+		//立直後に西家が切った
+		{Dots5, 0},
+		//見逃された五筒の筋
+		{Dots2, 3},
+		//立直前に切られた
+		{Dots1, 5},
+		{Dots9, 5},
+		{Dots3, 7},
+	}
+	for _, tt := range tests {
+		if got := Danger(tt.tileType, discards, visible); got != tt.want {
+			t.Errorf("Danger(%v) = %v, want %v", TilesName[tt.tileType], got, tt.want)
+		}
+	}
+}
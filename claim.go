@@ -0,0 +1,242 @@
+package mahjong
+
+import "errors"
+
+//打牌への宣言、優先順位の低い順
+type ClaimType int8
+
+const (
+	ClaimPass ClaimType = iota
+	ClaimChii
+	ClaimPon
+	ClaimKan
+	ClaimRon
+)
+
+var ClaimNames = map[ClaimType]string{
+	ClaimPass: "Pass",
+	ClaimChii: "Chii",
+	ClaimPon:  "Pon",
+	ClaimKan:  "Kan",
+	ClaimRon:  "Ron",
+}
+
+type Claim struct {
+	ClaimType
+	//チー・ポンで晒す手牌
+	Tiles []Tile
+}
+
+//打牌後の受付、全員の宣言が揃ってから優先順位で解決する
+type ClaimWindow struct {
+	Tile
+	Discarder *Player
+	Options   map[FieldWind][]ClaimType
+	Claims    map[FieldWind]Claim
+}
+
+func (window *ClaimWindow) CanClaim(player *Player, claimType ClaimType) bool {
+	if claimType == ClaimPass {
+		_, ok := window.Options[player.FieldWind]
+		return ok
+	}
+	for _, option := range window.Options[player.FieldWind] {
+		if option == claimType {
+			return true
+		}
+	}
+	return false
+}
+
+//チー・ポンは晒す二枚が手牌にあり、打牌と面子になること
+func (window *ClaimWindow) checkTiles(player *Player, claim Claim) error {
+	var isMeld func(ttA, ttB, ttC TileType) bool
+	switch claim.ClaimType {
+	case ClaimChii:
+		isMeld = IsXYZ
+	case ClaimPon:
+		isMeld = IsXXX
+	default:
+		return nil
+	}
+	name := ClaimNames[claim.ClaimType]
+	if len(claim.Tiles) != 2 {
+		return errors.New(name + " needs two tiles. ")
+	}
+	if _, err := player.GetTilesIndexes(claim.Tiles...); err != nil {
+		return err
+	}
+	if !isMeld(claim.Tiles[0].TileType, claim.Tiles[1].TileType, window.Tile.TileType) {
+		return errors.New("Can not " + name + " the tile with them. ")
+	}
+	return nil
+}
+
+//全員が宣言したか
+func (window *ClaimWindow) Complete() bool {
+	return len(window.Claims) == len(window.Options)
+}
+
+type ClaimResult struct {
+	ClaimType
+	Players   []*Player
+	Wins      []*WinResult
+	Ryuukyoku *RyuukyokuResult
+}
+
+//打牌に鳴き・ロンできる人がいれば受付を開く
+func (maj *Mahjong) openClaimWindow() {
+	window := &ClaimWindow{
+		Tile:      maj.LastTile,
+		Discarder: maj.LastTilePlayer,
+		Options:   make(map[FieldWind][]ClaimType),
+		Claims:    make(map[FieldWind]Claim),
+	}
+	maj.Players.Do(
+		func(player *Player) {
+			if player == window.Discarder {
+				return
+			}
+			options := make([]ClaimType, 0)
+			if _, err := maj.CanChii(player); err == nil {
+				options = append(options, ClaimChii)
+			}
+			if _, err := maj.CanPon(player); err == nil {
+				options = append(options, ClaimPon)
+			}
+			if _, err := maj.CanKan(player); err == nil {
+				options = append(options, ClaimKan)
+			}
+			if _, err := maj.CanRon(player); err == nil {
+				options = append(options, ClaimRon)
+			}
+			if len(options) != 0 {
+				window.Options[player.FieldWind] = options
+			}
+		},
+	)
+	if len(window.Options) == 0 {
+		maj.ClaimWindow = nil
+		return
+	}
+	maj.ClaimWindow = window
+}
+
+func (maj *Mahjong) closeClaimWindow() {
+	maj.ClaimWindow = nil
+}
+
+//受付中は宣言でしか鳴けず、ツモもできない
+func (maj *Mahjong) checkClaimWindow() error {
+	if maj.ClaimWindow != nil {
+		return WaitingForClaims{}
+	}
+	return nil
+}
+
+//宣言が揃えば解決する、揃うまではnilを返す
+func (maj *Mahjong) Declare(player *Player, claim Claim) (*ClaimResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
+	window := maj.ClaimWindow
+	if window == nil {
+		return nil, errors.New("No claim window. ")
+	}
+	if !window.CanClaim(player, claim.ClaimType) {
+		return nil, errors.New("Player can not " + ClaimNames[claim.ClaimType] + ". ")
+	}
+	if _, ok := window.Claims[player.FieldWind]; ok {
+		return nil, errors.New("Player has declared. ")
+	}
+	if err := window.checkTiles(player, claim); err != nil {
+		return nil, err
+	}
+	window.Claims[player.FieldWind] = claim
+	if !window.Complete() {
+		//解決した時は鳴き・ロンの方を記録する
+		maj.record(Event{Type: EventDeclare, Seats: seats(player), Tiles: claim.Tiles, ClaimType: claim.ClaimType})
+		return nil, nil
+	}
+	return maj.ResolveClaims()
+}
+
+//時間切れの人はパスとして解決する
+func (maj *Mahjong) ResolveClaims() (*ClaimResult, error) {
+	window := maj.ClaimWindow
+	if window == nil {
+		return nil, errors.New("No claim window. ")
+	}
+	best := ClaimPass
+	players := make([]*Player, 0)
+	maj.Players.Do(
+		func(player *Player) {
+			claim, ok := window.Claims[player.FieldWind]
+			if !ok || claim.ClaimType < best {
+				return
+			}
+			if claim.ClaimType > best {
+				best = claim.ClaimType
+				players = players[:0]
+			}
+			players = append(players, player)
+		},
+	)
+	result := &ClaimResult{ClaimType: best}
+	if best == ClaimPass {
+		maj.closeClaimWindow()
+		maj.closeChankan()
+		maj.record(Event{Type: EventResolveClaims})
+		return result, nil
+	}
+	players = maj.ronOrder(players)
+	if best == ClaimRon {
+		return maj.resolveRon(result, players)
+	}
+	if err := maj.call(players[0], window.Claims[players[0].FieldWind]); err != nil {
+		return nil, err
+	}
+	result.Players = players[:1]
+	return result, nil
+}
+
+//宣言した鳴きをして、できてから受付を閉じる
+func (maj *Mahjong) call(player *Player, claim Claim) error {
+	var err error
+	switch claim.ClaimType {
+	case ClaimKan:
+		err = maj.kan(player)
+	case ClaimPon:
+		err = maj.pon(player, claim.Tiles[0], claim.Tiles[1])
+	case ClaimChii:
+		err = maj.chii(player, claim.Tiles[0], claim.Tiles[1])
+	default:
+		err = errors.New("Can not call by " + ClaimNames[claim.ClaimType] + ". ")
+	}
+	if err != nil {
+		return err
+	}
+	maj.closeClaimWindow()
+	return nil
+}
+
+//三家和、ダブロン、頭ハネの順に試す
+func (maj *Mahjong) resolveRon(result *ClaimResult, players []*Player) (*ClaimResult, error) {
+	if len(players) == 3 {
+		if ryuukyoku, err := maj.tripleRon(players...); err == nil {
+			result.Players = players
+			result.Ryuukyoku = ryuukyoku
+			return result, nil
+		}
+	}
+	if maj.Rule.CanMultiRon(players) != nil {
+		players = players[:1]
+	}
+	wins, err := maj.multiRon(players...)
+	if err != nil {
+		return nil, err
+	}
+	result.Players = players
+	result.Wins = wins
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package mahjong
+
+import "testing"
+
+//東家が5pを捨てた直後、南家はチー、西家はポンできる
+func newClaimTestGame(t *testing.T, southTiles, westTiles []TileType) (*Mahjong, *Player, *Player) {
+	maj := newTestGame(t)
+	junk := []TileType{
+		Characters1, Characters4, Characters7, Bamboo1, Bamboo4, Bamboo7, East, South, West, North, White,
+	}
+	north := maj.Players.FindField(NorthField)
+	north.Tiles = toSampleTiles(append([]TileType{Green, Green}, junk...))
+	south := maj.Players.FindField(SouthField)
+	south.Tiles = toSampleTiles(southTiles)
+	west := maj.Players.FindField(WestField)
+	west.Tiles = toSampleTiles(westTiles)
+	for i := range west.Tiles {
+		west.Tiles[i].Id = int8(i % 4)
+	}
+
+	east := maj.Players.Now()
+	east.Tiles[0] = Tile{TileType: Dots5, Id: 3}
+	if err := maj.Dahai(east, east.Tiles[0]); err != nil {
+		t.Fatal(err)
+	}
+	return maj, south, west
+}
+
+func TestMahjong_Declare(t *testing.T) {
+	junk := []TileType{
+		Characters1, Characters4, Characters7, Bamboo1, Bamboo4, Bamboo7, East, South, West, North, White,
+	}
+	maj, south, west := newClaimTestGame(
+		t,
+		append([]TileType{Dots3, Dots4}, junk...),
+		append([]TileType{Dots5, Dots5}, junk...),
+	)
+	if maj.ClaimWindow == nil || len(maj.ClaimWindow.Options) != 2 {
+		t.Fatalf("ClaimWindow = %+v", maj.ClaimWindow)
+	}
+	north := maj.Players.FindField(NorthField)
+	if _, err := maj.Declare(north, Claim{ClaimType: ClaimPass}); err == nil {
+		t.Error("Declare() by an ineligible player should fail")
+	}
+	if _, err := maj.Declare(south, Claim{ClaimType: ClaimPon}); err == nil {
+		t.Error("Declare() of an unavailable claim should fail")
+	}
+
+	result, err := maj.Declare(south, Claim{ClaimChii, []Tile{south.Tiles[0], south.Tiles[1]}})
+	if err != nil || result != nil {
+		t.Fatalf("Declare() = %v, %v, want to wait for west", result, err)
+	}
+	result, err = maj.Declare(west, Claim{ClaimPon, []Tile{west.Tiles[0], west.Tiles[1]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ClaimType != ClaimPon || result.Players[0] != west || !maj.IsTurn(west) || west.Phase != RemoveTile {
+		t.Errorf("Declare() = %+v, want pon by west", result)
+	}
+	if south.Phase != Idle || len(south.XYZs) != 0 || maj.ClaimWindow != nil {
+		t.Errorf("south phase = %v, chii = %v", south.Phase.Name(), south.XYZs)
+	}
+}
+
+func TestMahjong_ResolveClaims(t *testing.T) {
+	waiting := []TileType{
+		Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+		Dots2, Dots2, Dots4, Dots6,
+	}
+	tests := []struct {
+		name    string
+		options *RuleOptions
+		ron     int
+		want    []FieldWind
+	}{
+		{"頭ハネ", &DefaultRuleOptions, 2, []FieldWind{SouthField}},
+		{"ダブロン", &TenhouRuleOptions, 2, []FieldWind{SouthField, WestField}},
+		{"時間切れ", &TenhouRuleOptions, 1, []FieldWind{SouthField}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj, south, west := newClaimTestGame(t, waiting, waiting)
+				maj.Rule = &JapaneseHanChanRule{JapaneseBaseRule{BaseRule{maj}, tt.options}}
+				//西家から宣言しても下家の南家が優先
+				claimers := []*Player{west, south}[2-tt.ron:]
+				var result *ClaimResult
+				var err error
+				for _, player := range claimers {
+					result, err = maj.Declare(player, Claim{ClaimType: ClaimRon})
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				if result == nil {
+					if result, err = maj.ResolveClaims(); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if result.ClaimType != ClaimRon || len(result.Wins) != len(tt.want) {
+					t.Fatalf("ResolveClaims() = %+v", result)
+				}
+				for i, win := range result.Wins {
+					if win.Winner.FieldWind != tt.want[i] {
+						t.Errorf("Wins[%v] = %v, want %v", i, win.Winner.FieldWind, tt.want[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestMahjong_ResolveClaims_Pass(t *testing.T) {
+	junk := []TileType{
+		Characters1, Characters4, Characters7, Bamboo1, Bamboo4, Bamboo7, East, South, West, North, White,
+	}
+	maj, south, west := newClaimTestGame(
+		t,
+		append([]TileType{Dots3, Dots4}, junk...),
+		append([]TileType{Dots5, Dots5}, junk...),
+	)
+	if _, err := maj.Declare(west, Claim{ClaimType: ClaimPass}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := maj.ResolveClaims()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ClaimType != ClaimPass || maj.ClaimWindow != nil {
+		t.Errorf("ResolveClaims() = %+v", result)
+	}
+	if _, err := maj.Draw(south); err != nil {
+		t.Error(err)
+	}
+}
+
+//晒す牌の誤った宣言は受け付けず、他の宣言と打牌はそのまま
+func TestMahjong_Declare_Tiles(t *testing.T) {
+	junk := []TileType{
+		Characters1, Characters4, Characters7, Bamboo1, Bamboo4, Bamboo7, East, South, West, North, White,
+	}
+	maj, south, west := newClaimTestGame(
+		t,
+		append([]TileType{Dots3, Dots4}, junk...),
+		append([]TileType{Dots5, Dots5}, junk...),
+	)
+	if _, err := maj.Declare(south, Claim{ClaimChii, []Tile{south.Tiles[0], south.Tiles[1]}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		tiles []Tile
+	}{
+		{"one tile", []Tile{west.Tiles[0]}},
+		{"not in hand", []Tile{west.Tiles[0], {TileType: Dots5, Id: 3}}},
+		{"not a pung", []Tile{west.Tiles[0], west.Tiles[2]}},
+	}
+	for _, tt := range tests {
+		if _, err := maj.Declare(west, Claim{ClaimPon, tt.tiles}); err == nil {
+			t.Errorf("Declare() with %v should fail", tt.name)
+		}
+	}
+	if maj.ClaimWindow == nil || len(maj.ClaimWindow.Claims) != 1 || maj.LastTile.TileType != Dots5 {
+		t.Fatalf("ClaimWindow = %+v, LastTile = %v", maj.ClaimWindow, maj.LastTile)
+	}
+	result, err := maj.Declare(west, Claim{ClaimType: ClaimPass})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ClaimType != ClaimChii || len(south.XYZs) != 1 || maj.ClaimWindow != nil {
+		t.Errorf("Declare() = %+v, want chii by south", result)
+	}
+}
+
+//受付中はツモできず、直接の鳴きは宣言になる
+func TestMahjong_ClaimWindow_Wait(t *testing.T) {
+	junk := []TileType{
+		Characters1, Characters4, Characters7, Bamboo1, Bamboo4, Bamboo7, East, South, West, North, White,
+	}
+	maj, south, west := newClaimTestGame(
+		t,
+		append([]TileType{Dots3, Dots4}, junk...),
+		append([]TileType{Dots5, Dots5}, junk...),
+	)
+	if _, err := maj.Draw(south); err != (WaitingForClaims{}) {
+		t.Errorf("Draw() = %v, want WaitingForClaims", err)
+	}
+	if err := maj.Pon(west, west.Tiles[0], west.Tiles[1]); err != nil {
+		t.Fatal(err)
+	}
+	if maj.ClaimWindow == nil || len(west.XXXs) != 0 {
+		t.Errorf("Pon() should wait for south, ClaimWindow = %+v", maj.ClaimWindow)
+	}
+	//チーよりポンが優先
+	if err := maj.Chii(south, south.Tiles[0], south.Tiles[1]); err != nil {
+		t.Fatal(err)
+	}
+	if maj.ClaimWindow != nil || len(west.XXXs) != 1 || len(south.XYZs) != 0 {
+		t.Errorf("west = %+v, south = %+v", west.XXXs, south.XYZs)
+	}
+	if maj.Players.Now() != west || west.Phase != RemoveTile {
+		t.Errorf("Now() = %v, want west to discard", maj.Players.Now().FieldWind)
+	}
+}
+
+//受付中の直接のロンは頭ハネを飛ばせない
+func TestMahjong_ClaimWindow_Ron(t *testing.T) {
+	waiting := []TileType{
+		Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+		Dots2, Dots2, Dots4, Dots6,
+	}
+	maj, south, west := newClaimTestGame(t, waiting, waiting)
+	if _, err := maj.Ron(west); err != (WaitingForClaims{}) {
+		t.Errorf("Ron() = %v, want WaitingForClaims", err)
+	}
+	if _, err := maj.MultiRon(west, south); err != (WaitingForClaims{}) {
+		t.Errorf("MultiRon() = %v, want WaitingForClaims", err)
+	}
+	if _, err := maj.Declare(west, Claim{ClaimType: ClaimRon}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := maj.Declare(south, Claim{ClaimType: ClaimRon})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Wins) != 1 || result.Wins[0].Winner != south {
+		t.Errorf("Declare() = %+v, want south by atamahane", result)
+	}
+}
+
+//ロンを見逃して他家がポンしたら同巡内フリテン
+func TestMahjong_ResolveClaims_Through(t *testing.T) {
+	waiting := []TileType{
+		Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+		Dots2, Dots2, Dots4, Dots6,
+	}
+	junk := []TileType{
+		Characters1, Characters4, Characters7, Bamboo1, Bamboo4, Bamboo7, East, South, West, North, White,
+	}
+	maj, south, west := newClaimTestGame(t, waiting, append([]TileType{Dots5, Dots5}, junk...))
+	if _, err := maj.Declare(south, Claim{ClaimType: ClaimPass}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := maj.Declare(west, Claim{ClaimPon, []Tile{west.Tiles[0], west.Tiles[1]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ClaimType != ClaimPon || !south.Through {
+		t.Errorf("Declare() = %+v, through = %v", result, south.Through)
+	}
+}
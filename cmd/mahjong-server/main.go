@@ -0,0 +1,18 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	timeout := flag.Duration("timeout", 20*time.Second, "time for each turn and claim")
+	flag.Parse()
+
+	http.Handle("/ws", newServer(*timeout))
+	log.Println("listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,112 @@
+package main
+
+import "github.com/zitem/mahjong"
+
+//クライアントから送るメッセージ、一つのテキストフレームに一つ
+//
+//	{"Type": "join", "Table": "room1"}
+//	{"Type": "draw"}
+//	{"Type": "dahai", "Tiles": [{"TileType": 10, "Id": 0}]}
+//	{"Type": "pon", "Tiles": [{"TileType": 28, "Id": 1}, {"TileType": 28, "Id": 2}]}
+//	{"Type": "ankan", "TileType": 28}
+type clientMessage struct {
+	Type     string
+	Table    string           `json:",omitempty"`
+	Tiles    []mahjong.Tile   `json:",omitempty"`
+	TileType mahjong.TileType `json:",omitempty"`
+}
+
+//鳴きの受付中に送れるもの
+var claimTypes = map[string]mahjong.ClaimType{
+	"pass": mahjong.ClaimPass,
+	"chii": mahjong.ClaimChii,
+	"pon":  mahjong.ClaimPon,
+	"kan":  mahjong.ClaimKan,
+	"ron":  mahjong.ClaimRon,
+}
+
+//サーバーから送るメッセージ
+//
+//	joined   参加した卓と席
+//	view     その席から見える状態、状態が変わるたびに送る
+//	result   局の結果、和了者の手牌を含む
+//	gameover 終局の順位
+//	error    直前のメッセージが受け付けられなかった
+type serverMessage struct {
+	Type       string
+	Table      string `json:",omitempty"`
+	Seat       mahjong.FieldWind
+	View       *mahjong.View      `json:",omitempty"`
+	Wins       []winMessage       `json:",omitempty"`
+	Ryuukyoku  *ryuukyokuMessage  `json:",omitempty"`
+	Placements []placementMessage `json:",omitempty"`
+	Error      string             `json:",omitempty"`
+}
+
+//Playerの代わりに席を書く、ツモならDiscarderはなし
+type winMessage struct {
+	Winner      mahjong.FieldWind
+	Discarder   *mahjong.FieldWind `json:",omitempty"`
+	Tsumo       bool
+	WinningTile mahjong.Tile
+	Hand        *mahjong.Hand
+	YakuTachi   []mahjong.YakuFan
+	Fan         mahjong.Fan
+	Fu          int
+	Limit       string `json:",omitempty"`
+	Deltas      [4]int
+}
+
+type ryuukyokuMessage struct {
+	Type    mahjong.DrawType
+	Name    string
+	Tenpai  [4]bool
+	Deltas  [4]int
+	Renchan bool
+}
+
+type placementMessage struct {
+	Seat  mahjong.FieldWind
+	Rank  int
+	Score int
+	Point float64
+}
+
+func newWinMessage(result *mahjong.WinResult, akaDora int) winMessage {
+	message := winMessage{
+		Winner:      result.Winner.FieldWind,
+		Tsumo:       result.Tsumo,
+		WinningTile: result.WinningTile,
+		Hand:        result.Winner.Hand(akaDora),
+		YakuTachi:   result.YakuTachi,
+		Fan:         result.Fan,
+		Fu:          result.Fu,
+		Limit:       result.Limit,
+		Deltas:      result.Deltas,
+	}
+	if result.Discarder != nil {
+		discarder := result.Discarder.FieldWind
+		message.Discarder = &discarder
+	}
+	return message
+}
+
+func newRyuukyokuMessage(result *mahjong.RyuukyokuResult) *ryuukyokuMessage {
+	return &ryuukyokuMessage{
+		Type:    result.Type,
+		Name:    mahjong.DrawNames[result.Type],
+		Tenpai:  result.Tenpai,
+		Deltas:  result.Deltas,
+		Renchan: result.Renchan,
+	}
+}
+
+func newPlacementMessages(gameOver *mahjong.GameOver) []placementMessage {
+	placements := make([]placementMessage, 0, len(gameOver.Placements))
+	for _, placement := range gameOver.Placements {
+		placements = append(
+			placements, placementMessage{placement.Player.FieldWind, placement.Rank, placement.Score, placement.Point},
+		)
+	}
+	return placements
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zitem/mahjong"
+)
+
+//ツモは放銃者を書かない、ロンは東家の放銃も書く
+func TestNewWinMessage_Discarder(t *testing.T) {
+	east := &mahjong.Player{FieldWind: mahjong.EastField}
+	south := &mahjong.Player{FieldWind: mahjong.SouthField}
+	tsumo, err := json.Marshal(newWinMessage(&mahjong.WinResult{Winner: south, Tsumo: true}, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(tsumo), "Discarder") {
+		t.Errorf("tsumo message = %s", tsumo)
+	}
+	ron := newWinMessage(&mahjong.WinResult{Winner: south, Discarder: east}, 0)
+	if ron.Discarder == nil || *ron.Discarder != mahjong.EastField {
+		t.Errorf("ron Discarder = %v, want East", ron.Discarder)
+	}
+}
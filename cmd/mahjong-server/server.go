@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zitem/mahjong"
+)
+
+//送信待ちがこれを超えたら切断する
+const sendBuffer = 64
+
+type server struct {
+	mutex   sync.Mutex
+	tables  map[string]*table
+	timeout time.Duration
+	newRule func() mahjong.Rule
+	seed    func() int64
+}
+
+func newServer(timeout time.Duration) *server {
+	return &server{
+		tables:  make(map[string]*table),
+		timeout: timeout,
+		newRule: func() mahjong.Rule {
+			return &mahjong.JapaneseHanChanRule{}
+		},
+		seed: func() int64 {
+			return time.Now().UnixNano()
+		},
+	}
+}
+
+//卓がなければ作る
+func (server *server) join(client *client, id string) error {
+	if client.table != nil {
+		return errors.New("Already joined a table. ")
+	}
+	if id == "" {
+		return errors.New("Table is required. ")
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	t, ok := server.tables[id]
+	if !ok {
+		t = newTable(id, server)
+		server.tables[id] = t
+	}
+	return t.join(client)
+}
+
+//誰もいなくなった卓を片付ける
+func (server *server) leave(client *client) {
+	t := client.table
+	if t == nil {
+		return
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if t.leave(client) && server.tables[t.id] == t {
+		delete(server.tables, t.id)
+	}
+}
+
+func (server *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrade(w, r)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	client := newClient(conn)
+	go client.writeLoop()
+	defer client.close()
+	defer server.leave(client)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var message clientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			client.sendError(err)
+			continue
+		}
+		switch {
+		case message.Type == "join":
+			if err := server.join(client, message.Table); err != nil {
+				client.sendError(err)
+			}
+		case client.table == nil:
+			client.sendError(errors.New("Join a table first. "))
+		default:
+			client.table.act(client, message)
+		}
+	}
+}
+
+type client struct {
+	conn  *wsConn
+	out   chan []byte
+	done  chan struct{}
+	once  sync.Once
+	table *table
+	seat  mahjong.FieldWind
+}
+
+func newClient(conn *wsConn) *client {
+	return &client{conn: conn, out: make(chan []byte, sendBuffer), done: make(chan struct{})}
+}
+
+//卓を止めないように書き込みは別のgoroutineで行う
+func (client *client) send(message serverMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	select {
+	case client.out <- data:
+	case <-client.done:
+	default:
+		client.close()
+	}
+}
+
+func (client *client) sendError(err error) {
+	client.send(serverMessage{Type: "error", Error: err.Error()})
+}
+
+func (client *client) writeLoop() {
+	for {
+		select {
+		case data := <-client.out:
+			if err := client.conn.WriteMessage(opText, data); err != nil {
+				client.close()
+				return
+			}
+		case <-client.done:
+			return
+		}
+	}
+}
+
+func (client *client) close() {
+	client.once.Do(
+		func() {
+			close(client.done)
+			client.conn.Close()
+		},
+	)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zitem/mahjong"
+)
+
+type testClient struct {
+	t  *testing.T
+	ws *wsConn
+}
+
+func newTestServer(t *testing.T, timeout time.Duration) (*server, string, func()) {
+	server := newServer(timeout)
+	server.seed = func() int64 {
+		return 1
+	}
+	httpServer := httptest.NewServer(server)
+	return server, "ws" + strings.TrimPrefix(httpServer.URL, "http"), httpServer.Close
+}
+
+func connect(t *testing.T, url string) *testClient {
+	ws, err := dial(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testClient{t, ws}
+}
+
+func (c *testClient) send(message clientMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := c.ws.WriteMessage(opText, data); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+//指定した種類のメッセージまで読み飛ばす
+func (c *testClient) next(messageType string) (serverMessage, error) {
+	c.ws.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return serverMessage{}, err
+		}
+		var message serverMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			return serverMessage{}, err
+		}
+		if message.Type == messageType {
+			return message, nil
+		}
+		if message.Type == "error" {
+			return serverMessage{}, errors.New(message.Error)
+		}
+	}
+}
+
+func (c *testClient) expect(messageType string) serverMessage {
+	c.t.Helper()
+	message, err := c.next(messageType)
+	if err != nil {
+		c.t.Fatalf("waiting for %v: %v", messageType, err)
+	}
+	return message
+}
+
+func joinTable(t *testing.T, url, table string) []*testClient {
+	clients := make([]*testClient, 4)
+	for seat := range clients {
+		clients[seat] = connect(t, url)
+		clients[seat].send(clientMessage{Type: "join", Table: table})
+		if joined := clients[seat].expect("joined"); joined.Seat != mahjong.FieldWind(seat) || joined.Table != table {
+			t.Fatalf("joined = %+v", joined)
+		}
+	}
+	return clients
+}
+
+func TestServer_Play(t *testing.T) {
+	_, url, closeServer := newTestServer(t, time.Minute)
+	defer closeServer()
+	clients := joinTable(t, url, "a")
+
+	views := make([]*mahjong.View, 4)
+	for seat, c := range clients {
+		views[seat] = c.expect("view").View
+		for _, s := range views[seat].Seats {
+			if s.Tiles != nil {
+				t.Errorf("seat %v sees the hand of seat %v", seat, s.FieldWind)
+			}
+		}
+	}
+	dealer := views[0].Turn
+	if !views[dealer].Actions.Dahai || len(views[dealer].Hand) != 14 {
+		t.Fatalf("dealer view = %+v", views[dealer])
+	}
+
+	//手番でない人は打てない
+	other := clients[(dealer+1)%4]
+	other.send(clientMessage{Type: "draw"})
+	if message := other.expect("error"); message.Error == "" {
+		t.Error("draw out of turn should fail")
+	}
+
+	clients[dealer].send(clientMessage{Type: "dahai", Tiles: []mahjong.Tile{views[dealer].LastDraw}})
+	for seat, c := range clients {
+		view := c.expect("view").View
+		if discards := view.Seats[dealer].Discards; len(discards) != 1 || !discards[0].TsumoGiri {
+			t.Errorf("seat %v sees discards %+v", seat, discards)
+		}
+		views[seat] = view
+	}
+	for seat, view := range views {
+		if view.Claims != nil {
+			clients[seat].send(clientMessage{Type: "pass"})
+		}
+	}
+	next := (dealer + 1) % 4
+	for view := views[next]; !view.Actions.Draw || view.Claims != nil; {
+		view = clients[next].expect("view").View
+	}
+	clients[next].send(clientMessage{Type: "draw"})
+	if view := clients[next].expect("view").View; len(view.Hand) != 14 || !view.Actions.Dahai {
+		t.Errorf("view after draw = %+v", view)
+	}
+}
+
+func TestServer_Tables(t *testing.T) {
+	server, url, closeServer := newTestServer(t, time.Minute)
+	defer closeServer()
+
+	lonely := connect(t, url)
+	lonely.send(clientMessage{Type: "draw"})
+	lonely.expect("error")
+
+	joinTable(t, url, "a")
+	lonely.send(clientMessage{Type: "join", Table: "a"})
+	if message := lonely.expect("error"); !strings.Contains(message.Error, "full") {
+		t.Errorf("error = %v", message.Error)
+	}
+	lonely.send(clientMessage{Type: "join", Table: "b"})
+	if joined := lonely.expect("joined"); joined.Table != "b" || joined.Seat != mahjong.EastField {
+		t.Errorf("joined = %+v", joined)
+	}
+	lonely.send(clientMessage{Type: "draw"})
+	lonely.expect("error")
+
+	//誰もいなくなった卓は消える
+	lonely.ws.Close()
+	for i := 0; ; i++ {
+		server.mutex.Lock()
+		_, ok := server.tables["b"]
+		n := len(server.tables)
+		server.mutex.Unlock()
+		if !ok {
+			if n != 1 {
+				t.Errorf("tables = %v, want 1", n)
+			}
+			break
+		}
+		if i > 100 {
+			t.Fatal("table b should be removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+//誰も打たなくても時間切れで局が進む
+func TestServer_Timeout(t *testing.T) {
+	_, url, closeServer := newTestServer(t, 2*time.Millisecond)
+	defer closeServer()
+	clients := joinTable(t, url, "a")
+
+	errs := make(chan error, len(clients))
+	for _, c := range clients {
+		go func(c *testClient) {
+			result, err := c.next("result")
+			if err == nil && result.Wins == nil && result.Ryuukyoku == nil {
+				err = errors.New("empty result")
+			}
+			//次局も始まる
+			for err == nil {
+				var message serverMessage
+				if message, err = c.next("view"); err == nil && message.View.ResultType == mahjong.NoResult {
+					break
+				}
+			}
+			errs <- err
+		}(c)
+	}
+	for range clients {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
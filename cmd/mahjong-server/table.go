@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zitem/mahjong"
+)
+
+//一つの卓、四人揃ったら始める
+type table struct {
+	mahjong.NopListener
+	id      string
+	server  *server
+	mutex   sync.Mutex
+	clients [4]*client
+	maj     *mahjong.Mahjong
+	//次局へ進んでよい席
+	ready [4]bool
+	timer *time.Timer
+	//古いタイマーを無視するための番号
+	generation int
+	//Listenerで受け取った結果、操作の後にまとめて送る
+	notices []serverMessage
+}
+
+func newTable(id string, server *server) *table {
+	return &table{id: id, server: server}
+}
+
+func (table *table) join(client *client) error {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	for seat, c := range table.clients {
+		if c != nil {
+			continue
+		}
+		table.clients[seat] = client
+		client.table = table
+		client.seat = mahjong.FieldWind(seat)
+		client.send(serverMessage{Type: "joined", Table: table.id, Seat: client.seat})
+		if table.maj != nil {
+			table.sendView(client)
+			return nil
+		}
+		if table.seated() == len(table.clients) {
+			return table.start()
+		}
+		return nil
+	}
+	return errors.New("Table is full. ")
+}
+
+//抜けた席は時間切れで自動に打つ
+func (table *table) leave(client *client) bool {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if table.clients[client.seat] == client {
+		table.clients[client.seat] = nil
+	}
+	if table.seated() != 0 {
+		return false
+	}
+	table.stopTimer()
+	return true
+}
+
+func (table *table) seated() int {
+	n := 0
+	for _, c := range table.clients {
+		if c != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (table *table) start() error {
+	table.maj = mahjong.InitWithSeed(table.server.newRule(), table.server.seed())
+	table.maj.Listener = table
+	if err := table.maj.Start(); err != nil {
+		return err
+	}
+	table.update()
+	return nil
+}
+
+func (table *table) act(client *client, message clientMessage) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if table.maj == nil {
+		client.sendError(errors.New("Game has not started. "))
+		return
+	}
+	if err := table.apply(client.seat, message); err != nil {
+		client.sendError(err)
+		return
+	}
+	table.update()
+}
+
+func (table *table) apply(seat mahjong.FieldWind, message clientMessage) error {
+	maj := table.maj
+	player := maj.Players.FindField(seat)
+	tile := mahjong.Tile{}
+	if len(message.Tiles) != 0 {
+		tile = message.Tiles[0]
+	}
+	if message.Type == "restart" {
+		return table.readyNext(seat)
+	}
+	if claimType, ok := claimTypes[message.Type]; ok && maj.ClaimWindow != nil {
+		_, err := maj.Declare(player, mahjong.Claim{ClaimType: claimType, Tiles: message.Tiles})
+		return err
+	}
+	if maj.ClaimWindow != nil {
+		return errors.New("Waiting for claims. ")
+	}
+	var err error
+	switch message.Type {
+	case "draw":
+		_, err = maj.Draw(player)
+	case "drawkan":
+		_, err = maj.DrawKan(player)
+	case "dahai":
+		err = maj.Dahai(player, tile)
+	case "riichi":
+		err = maj.Riichi(player, tile)
+	case "openriichi":
+		err = maj.OpenRiichi(player, tile)
+	case "ankan":
+		err = maj.AnKan(player, message.TileType)
+	case "kakan":
+		err = maj.KaKan(player, tile)
+	case "tsumo":
+		_, err = maj.Tsumo(player)
+	case "nineyaochus":
+		_, err = maj.NineYaochus(player)
+	case "pass", "chii", "pon", "kan", "ron":
+		err = errors.New("No claim window. ")
+	default:
+		err = errors.New("Unknown message type " + message.Type + ". ")
+	}
+	return err
+}
+
+//接続している全員が望むか時間切れで次局へ
+func (table *table) readyNext(seat mahjong.FieldWind) error {
+	if err := table.maj.CanRestart(); err != nil {
+		return err
+	}
+	table.ready[seat] = true
+	for s, c := range table.clients {
+		if c != nil && !table.ready[s] {
+			return nil
+		}
+	}
+	return table.restart()
+}
+
+func (table *table) restart() error {
+	table.ready = [4]bool{}
+	return table.maj.Restart()
+}
+
+//誰も選べない流局は自動で進める
+func (table *table) advance() {
+	maj := table.maj
+	if maj.GameOver != nil || maj.Result.Done() || maj.ClaimWindow != nil {
+		return
+	}
+	if maj.Players.Now().Phase != mahjong.AddTile {
+		return
+	}
+	if _, err := maj.CanRyuukyoku(); err == nil {
+		maj.Ryuukyoku()
+	}
+}
+
+//時間切れの席の代わりに打つ
+func (table *table) timeout(generation int) {
+	table.mutex.Lock()
+	defer table.mutex.Unlock()
+	if generation != table.generation {
+		return
+	}
+	maj := table.maj
+	switch {
+	case maj.GameOver != nil:
+		return
+	case maj.Result.Done():
+		table.restart()
+	case maj.ClaimWindow != nil:
+		maj.ResolveClaims()
+	default:
+		player := maj.Players.Now()
+		switch player.Phase {
+		case mahjong.AddTile:
+			maj.Draw(player)
+		case mahjong.AddTileKan:
+			maj.DrawKan(player)
+		case mahjong.RemoveTile:
+			//鳴いた後はツモ牌がないので最後の牌を切る
+			if err := maj.Dahai(player, player.LastDraw); err != nil {
+				maj.Dahai(player, player.Tiles[len(player.Tiles)-1])
+			}
+		}
+	}
+	table.update()
+}
+
+func (table *table) update() {
+	table.advance()
+	for _, notice := range table.notices {
+		table.broadcast(notice)
+	}
+	table.notices = nil
+	for _, c := range table.clients {
+		if c != nil {
+			table.sendView(c)
+		}
+	}
+	table.schedule()
+}
+
+func (table *table) schedule() {
+	table.stopTimer()
+	if table.maj == nil || table.maj.GameOver != nil {
+		return
+	}
+	generation := table.generation
+	table.timer = time.AfterFunc(
+		table.server.timeout, func() {
+			table.timeout(generation)
+		},
+	)
+}
+
+func (table *table) stopTimer() {
+	table.generation++
+	if table.timer != nil {
+		table.timer.Stop()
+		table.timer = nil
+	}
+}
+
+func (table *table) sendView(client *client) {
+	view, err := table.maj.ViewFor(client.seat)
+	if err != nil {
+		client.sendError(err)
+		return
+	}
+	client.send(serverMessage{Type: "view", Table: table.id, Seat: client.seat, View: view})
+}
+
+func (table *table) broadcast(message serverMessage) {
+	for seat, c := range table.clients {
+		if c != nil {
+			message.Seat = mahjong.FieldWind(seat)
+			c.send(message)
+		}
+	}
+}
+
+//ダブロンは一つの結果にまとめる
+func (table *table) OnWin(result *mahjong.WinResult) {
+	if n := len(table.notices); n != 0 && table.notices[n-1].Type == "result" {
+		table.notices[n-1].Wins = append(table.notices[n-1].Wins, newWinMessage(result, table.maj.AkaDora()))
+		return
+	}
+	table.notices = append(
+		table.notices, serverMessage{Type: "result", Table: table.id, Wins: []winMessage{newWinMessage(result, table.maj.AkaDora())}},
+	)
+}
+
+func (table *table) OnRyuukyoku(result *mahjong.RyuukyokuResult) {
+	table.notices = append(
+		table.notices, serverMessage{Type: "result", Table: table.id, Ryuukyoku: newRyuukyokuMessage(result)},
+	)
+}
+
+func (table *table) OnGameOver(gameOver *mahjong.GameOver) {
+	table.notices = append(
+		table.notices, serverMessage{Type: "gameover", Table: table.id, Placements: newPlacementMessages(gameOver)},
+	)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+//テスト用のクライアント
+func dial(url string) (*wsConn, error) {
+	if !strings.HasPrefix(url, "ws://") {
+		return nil, errors.New("Only ws://is supported. ")
+	}
+	host := strings.TrimPrefix(url, "ws://")
+	path := "/"
+	if i := strings.Index(host, "/"); i >= 0 {
+		host, path = host[:i], host[i:]
+	}
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	request := "GET " + path + " HTTP/1.1\r\n" +
+		"Host: " + host + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, errors.New("Handshake failed: " + response.Status)
+	}
+	return &wsConn{conn: conn, reader: reader, client: true}, nil
+}
+
+func TestAcceptKey(t *testing.T) {
+	//RFC 6455の例
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey() = %v", got)
+	}
+}
+
+//net.Pipeと違い書き込みがバッファされる
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	clientSide, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverSide, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serverSide, clientSide
+}
+
+func TestWsConn_ReadMessage(t *testing.T) {
+	serverSide, clientSide := tcpPair(t)
+	server := &wsConn{conn: serverSide, reader: bufio.NewReader(serverSide)}
+	client := &wsConn{conn: clientSide, reader: bufio.NewReader(clientSide), client: true}
+	defer server.Close()
+	defer client.Close()
+
+	long := bytes.Repeat([]byte("a"), 70000)
+	go func() {
+		client.WriteMessage(opText, []byte("hello"))
+		client.WriteMessage(opText, long)
+		//分割されたメッセージの間にping
+		client.writeFrame(false, opText, []byte("frag"))
+		client.writeFrame(true, opPing, []byte("p"))
+		client.writeFrame(true, opContinuation, []byte("ment"))
+	}()
+	for _, want := range [][]byte{[]byte("hello"), long, []byte("fragment")} {
+		opcode, got, err := server.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opcode != opText || !bytes.Equal(got, want) {
+			t.Errorf("ReadMessage() = %v, %v bytes, want %v bytes", opcode, len(got), len(want))
+		}
+	}
+	//pingへの返事
+	fin, opcode, payload, err := client.readFrame()
+	if err != nil || !fin || opcode != opPong || string(payload) != "p" {
+		t.Errorf("pong = %v %v %q %v", fin, opcode, payload, err)
+	}
+}
+
+func TestWsConn_ReadMessage_Unmasked(t *testing.T) {
+	serverSide, clientSide := tcpPair(t)
+	server := &wsConn{conn: serverSide, reader: bufio.NewReader(serverSide)}
+	//サーバー同士だとマスクされない
+	peer := &wsConn{conn: clientSide, reader: bufio.NewReader(clientSide)}
+	defer server.Close()
+	defer peer.Close()
+	go peer.WriteMessage(opText, []byte("hello"))
+	if _, _, err := server.ReadMessage(); err == nil {
+		t.Error("ReadMessage() of an unmasked client frame should fail")
+	}
+}
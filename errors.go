@@ -11,6 +11,38 @@ func (err NotTurn) Error() string {
 	return "Not Player's Turn"
 }
 
+type HandOver struct{}
+
+func (err HandOver) Error() string {
+	return "Hand is over"
+}
+
+type WallExhausted struct{}
+
+func (err WallExhausted) Error() string {
+	return "No tiles can be drawn"
+}
+
+type AbortiveDraw struct {
+	DrawType
+}
+
+func (err AbortiveDraw) Error() string {
+	return "Hand is aborted by " + DrawNames[err.DrawType]
+}
+
+type WaitingForClaims struct{}
+
+func (err WaitingForClaims) Error() string {
+	return "Waiting for claims"
+}
+
+type GameIsOver struct{}
+
+func (err GameIsOver) Error() string {
+	return "Game is over"
+}
+
 type NoTiles struct {
 	Tiles []Tile
 }
@@ -38,3 +70,11 @@ func (err WrongPhase) Error() string {
 	true := strings.Join(str, "/")
 	return "Player phase should be " + true + ", but is " + Phase.Name(err.Wrong) + " now."
 }
+
+type InvalidNotation struct {
+	Notation string
+}
+
+func (err InvalidNotation) Error() string {
+	return "Invalid tile notation: " + err.Notation
+}
@@ -0,0 +1,193 @@
+package mahjong
+
+import (
+	"errors"
+	"strconv"
+)
+
+//状態を変える操作の記録
+type EventType int8
+
+const (
+	EventStart EventType = iota
+	EventRestart
+	EventDraw
+	EventDrawKan
+	EventDahai
+	EventChii
+	EventPon
+	EventKan
+	EventAnKan
+	EventKaKan
+	EventRiichi
+	EventOpenRiichi
+	EventRon
+	EventMultiRon
+	EventTsumo
+	EventNineYaochus
+	EventTripleRon
+	EventRyuukyoku
+	EventDeclare
+	EventResolveClaims
+	EventKita
+)
+
+var EventNames = map[EventType]string{
+	EventStart:         "start",
+	EventRestart:       "restart",
+	EventDraw:          "draw",
+	EventDrawKan:       "drawkan",
+	EventDahai:         "dahai",
+	EventChii:          "chii",
+	EventPon:           "pon",
+	EventKan:           "kan",
+	EventAnKan:         "ankan",
+	EventKaKan:         "kakan",
+	EventRiichi:        "riichi",
+	EventOpenRiichi:    "openriichi",
+	EventRon:           "ron",
+	EventMultiRon:      "multiron",
+	EventTsumo:         "tsumo",
+	EventNineYaochus:   "nineyaochus",
+	EventTripleRon:     "tripleron",
+	EventRyuukyoku:     "ryuukyoku",
+	EventDeclare:       "declare",
+	EventResolveClaims: "resolveclaims",
+	EventKita:          "kita",
+}
+
+//ファイルに残す時は名前で書く
+func (eventType EventType) MarshalText() ([]byte, error) {
+	name, ok := EventNames[eventType]
+	if !ok {
+		return nil, errors.New("Unknown event type " + strconv.Itoa(int(eventType)) + ". ")
+	}
+	return []byte(name), nil
+}
+
+func (eventType *EventType) UnmarshalText(text []byte) error {
+	for t, name := range EventNames {
+		if name == string(text) {
+			*eventType = t
+			return nil
+		}
+	}
+	return errors.New("Unknown event type " + string(text) + ". ")
+}
+
+//Seatsの先頭が操作した人、Tilesはツモ・打牌・鳴きの牌
+type Event struct {
+	Type      EventType
+	Seats     []FieldWind `json:",omitempty"`
+	Tiles     []Tile      `json:",omitempty"`
+	TileType  TileType    `json:",omitempty"`
+	ClaimType ClaimType   `json:",omitempty"`
+}
+
+func (maj *Mahjong) record(event Event) {
+	maj.Events = append(maj.Events, event)
+}
+
+func seats(players ...*Player) []FieldWind {
+	seats := make([]FieldWind, len(players))
+	for i, player := range players {
+		seats[i] = player.FieldWind
+	}
+	return seats
+}
+
+func (maj *Mahjong) findSeats(seats []FieldWind) ([]*Player, error) {
+	if len(seats) == 0 {
+		return nil, errors.New("Event has no seat. ")
+	}
+	players := make([]*Player, len(seats))
+	for i, seat := range seats {
+		if players[i] = maj.Players.FindField(seat); players[i] == nil {
+			return nil, errors.New("No player at seat " + strconv.Itoa(int(seat)) + ". ")
+		}
+	}
+	return players, nil
+}
+
+//同じシードから記録を順に適用して状態を作り直す
+func Replay(rule Rule, seed int64, events []Event) (*Mahjong, error) {
+	maj := InitWithSeed(rule, seed)
+	for i, event := range events {
+		if err := maj.apply(event); err != nil {
+			return maj, errors.New("event " + strconv.Itoa(i) + " " + EventNames[event.Type] + ": " + err.Error())
+		}
+	}
+	return maj, nil
+}
+
+func (maj *Mahjong) apply(event Event) error {
+	switch event.Type {
+	case EventStart:
+		return maj.Start()
+	case EventRestart:
+		return maj.Restart()
+	case EventRyuukyoku:
+		_, err := maj.Ryuukyoku()
+		return err
+	case EventResolveClaims:
+		_, err := maj.ResolveClaims()
+		return err
+	}
+	players, err := maj.findSeats(event.Seats)
+	if err != nil {
+		return err
+	}
+	player := players[0]
+	tile := func(i int) Tile {
+		if i < len(event.Tiles) {
+			return event.Tiles[i]
+		}
+		return Tile{}
+	}
+	switch event.Type {
+	case EventDraw, EventDrawKan:
+		draw := maj.Draw
+		if event.Type == EventDrawKan {
+			draw = maj.DrawKan
+		}
+		drawn, err := draw(player)
+		if err == nil && drawn != tile(0) {
+			err = errors.New("Drew " + TilesName[drawn.TileType] + " instead of " + TilesName[tile(0).TileType])
+		}
+		return err
+	case EventDahai:
+		return maj.Dahai(player, tile(0))
+	//鳴きは受付を解決した結果として記録される
+	case EventChii:
+		return maj.call(player, Claim{ClaimChii, []Tile{tile(0), tile(1)}})
+	case EventPon:
+		return maj.call(player, Claim{ClaimPon, []Tile{tile(0), tile(1)}})
+	case EventKan:
+		return maj.call(player, Claim{ClaimType: ClaimKan})
+	case EventAnKan:
+		return maj.AnKan(player, event.TileType)
+	case EventKaKan:
+		return maj.KaKan(player, tile(0))
+	case EventKita:
+		return maj.Kita(player, tile(0))
+	case EventRiichi:
+		return maj.Riichi(player, tile(0))
+	case EventOpenRiichi:
+		return maj.OpenRiichi(player, tile(0))
+	case EventRon:
+		_, err = maj.ron(player)
+	case EventMultiRon:
+		_, err = maj.multiRon(players...)
+	case EventTsumo:
+		_, err = maj.Tsumo(player)
+	case EventNineYaochus:
+		_, err = maj.NineYaochus(player)
+	case EventTripleRon:
+		_, err = maj.tripleRon(players...)
+	case EventDeclare:
+		_, err = maj.Declare(player, Claim{event.ClaimType, event.Tiles})
+	default:
+		err = errors.New("Unknown event. ")
+	}
+	return err
+}
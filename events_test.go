@@ -0,0 +1,134 @@
+package mahjong
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+//牌効率で打ち、ロンかポンできれば宣言する
+func playHands(t *testing.T, maj *Mahjong, hands int) {
+	for hand := 0; hand < hands; hand++ {
+		if hand > 0 {
+			if err := maj.Restart(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for !maj.Result.Done() {
+			playStep(t, maj)
+		}
+	}
+}
+
+func playStep(t *testing.T, maj *Mahjong) {
+	if window := maj.ClaimWindow; window != nil {
+		for seat := EastField; seat <= NorthField && maj.ClaimWindow != nil; seat++ {
+			if _, ok := window.Options[seat]; !ok {
+				continue
+			}
+			player := maj.Players.FindField(seat)
+			claim := Claim{ClaimType: ClaimPass}
+			if window.CanClaim(player, ClaimRon) {
+				claim.ClaimType = ClaimRon
+			} else if pons, err := maj.CanPon(player); err == nil {
+				claim = Claim{ClaimPon, pons[0][:]}
+			}
+			if _, err := maj.Declare(player, claim); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+	player := maj.Players.Now()
+	switch player.Phase {
+	case AddTile:
+		if _, err := maj.Draw(player); err != nil {
+			if _, err := maj.Ryuukyoku(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	case RemoveTile:
+		if _, err := maj.CanTsumo(player); err == nil {
+			if _, err := maj.Tsumo(player); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+		advices, err := maj.AdviseDiscards(player, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := maj.Dahai(player, advices[0].Tile); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unexpected phase %v", player.Phase.Name())
+	}
+}
+
+func TestReplay(t *testing.T) {
+	maj := newTestGame(t)
+	playHands(t, maj, 3)
+
+	replayed, err := Replay(&JapaneseHanChanRule{}, 1, maj.Events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayed.Events, maj.Events) {
+		t.Errorf("Events differ, %v/%v", len(replayed.Events), len(maj.Events))
+	}
+	if replayed.Round != maj.Round || !reflect.DeepEqual(replayed.Tiles, maj.Tiles) {
+		t.Errorf("Round = %+v, want %+v", replayed.Round, maj.Round)
+	}
+	for seat := EastField; seat <= NorthField; seat++ {
+		got, want := replayed.Players.FindField(seat), maj.Players.FindField(seat)
+		if got.Score != want.Score || !reflect.DeepEqual(got.Tiles, want.Tiles) ||
+			!reflect.DeepEqual(got.Discards, want.Discards) {
+			t.Errorf("seat %v = %v %v, want %v %v", seat, got.Score, got.Tiles, want.Score, want.Tiles)
+		}
+	}
+}
+
+func TestReplay_JSON(t *testing.T) {
+	maj := newTestGame(t)
+	playHands(t, maj, 1)
+
+	data, err := json.Marshal(maj.Events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(events, maj.Events) {
+		t.Fatalf("json round trip differs: %s", data)
+	}
+	if _, err := Replay(&JapaneseHanChanRule{}, 1, events); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplay_Invalid(t *testing.T) {
+	maj := newTestGame(t)
+	playHands(t, maj, 1)
+
+	events := append([]Event{}, maj.Events...)
+	for i, event := range events {
+		if event.Type == EventDraw {
+			event.Tiles = []Tile{{TileType: Red, Id: 3}}
+			events[i] = event
+			break
+		}
+	}
+	if _, err := Replay(&JapaneseHanChanRule{}, 1, events); err == nil {
+		t.Error("Replay() with a wrong draw should fail")
+	}
+	if _, err := Replay(&JapaneseHanChanRule{}, 2, maj.Events); err == nil {
+		t.Error("Replay() with another seed should fail")
+	}
+	var eventType EventType
+	if err := eventType.UnmarshalText([]byte("unknown")); err == nil {
+		t.Error("UnmarshalText() with an unknown name should fail")
+	}
+}
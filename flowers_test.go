@@ -0,0 +1,90 @@
+package mahjong
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+//花牌のあるルールの山を積まずに並べた局
+func newTestFlowerGame(rule Rule) *Mahjong {
+	maj := Init(rule)
+	maj.Tiles = maj.Rule.Tiles()
+	return maj
+}
+
+//花牌のあるルールの和了判定の例、flowersは花牌の表記
+type flowerAgariTest struct {
+	name    string
+	hand    string
+	last    string
+	tsumo   bool
+	flowers string
+	fan     Fan
+	want    []string
+}
+
+//南家の手牌で和了させ、一番高い和了の番数と番種名を比べる
+func testFlowerAgaris(t *testing.T, newRule func() Rule, tests []flowerAgariTest) {
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj := newTestFlowerGame(newRule())
+				player := maj.Players.FindField(SouthField)
+				hand, err := ParseHand(tt.hand)
+				if err != nil {
+					t.Fatal(err)
+				}
+				player.SetHand(hand)
+				last, err := ParseTile(tt.last)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.flowers != "" {
+					if player.Flowers, err = ParseTiles(tt.flowers); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if tt.tsumo {
+					player.Tiles = append(player.Tiles, last)
+					player.LastDraw = last
+					last = Tile{}
+				}
+				agaris, err := maj.Rule.CanAgari(player, last)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fan, names := bestFlowerAgari(agaris)
+				sort.Strings(tt.want)
+				if fan != tt.fan || !reflect.DeepEqual(names, tt.want) {
+					t.Errorf("CanAgari() = %v %v, want %v %v", fan, names, tt.fan, tt.want)
+				}
+			},
+		)
+	}
+}
+
+//一番高い和了と番種名
+func bestFlowerAgari(agaris []Agari) (Fan, []string) {
+	best := agaris[0]
+	for _, agari := range agaris[1:] {
+		if agari.Fan(true) > best.Fan(true) {
+			best = agari
+		}
+	}
+	names := make([]string, 0)
+	for _, yaku := range best.YakuTachi {
+		names = append(names, yaku.Name)
+	}
+	sort.Strings(names)
+	return best.Fan(true), names
+}
+
+//四人のボットで一半荘打たせる
+func simulateFlowerRule(t *testing.T, newRule func() Rule) *Statistics {
+	stats := Simulate(newRule, []Bot{ShantenBot{}, DefensiveBot{}, TsumogiriBot{}, ShantenBot{}}, []int64{1})
+	if len(stats.Errors) != 0 {
+		t.Fatal(stats.Errors)
+	}
+	return stats
+}
@@ -0,0 +1,95 @@
+package mahjong
+
+import sort2 "sort"
+
+//順位
+type Placement struct {
+	Player *Player
+	Rank   int
+	Score  int
+	//ウマ・オカ込み
+	Point float64
+}
+
+//終局
+type GameOver struct {
+	Placements []Placement
+}
+
+//同点は起家に近い方が上位
+func (maj *Mahjong) Placements() []Placement {
+	placements := make([]Placement, 0)
+	maj.Players.Do(
+		func(player *Player) {
+			placements = append(placements, Placement{Player: player, Score: player.Score})
+		},
+	)
+	sort2.Slice(
+		placements, func(i, j int) bool {
+			if placements[i].Score != placements[j].Score {
+				return placements[i].Score > placements[j].Score
+			}
+			return placements[i].Player.FieldWind < placements[j].Player.FieldWind
+		},
+	)
+	for i := range placements {
+		placements[i].Rank = i + 1
+	}
+	return placements
+}
+
+//局の終了、連荘と終局を決める
+func (maj *Mahjong) endHand() {
+	maj.Result.Renchan = maj.Rule.Renchan()
+	for _, win := range maj.Result.Wins {
+		maj.listener().OnWin(win)
+	}
+	if ryuukyoku := maj.Result.Ryuukyoku; ryuukyoku != nil {
+		ryuukyoku.Renchan = maj.Result.Renchan
+		maj.listener().OnRyuukyoku(ryuukyoku)
+	}
+	maj.ClaimWindow = nil
+	maj.Players.Do(
+		func(player *Player) {
+			player.Phase.Change(Idle)
+		},
+	)
+	if maj.Rule.IsGameOver(maj.Result.Renchan) {
+		//残った供託はトップへ
+		top := maj.Placements()[0].Player
+		top.Score += maj.Deposit
+		maj.Deposit = 0
+		placements := maj.Placements()
+		maj.Rule.Settle(placements)
+		maj.GameOver = &GameOver{Placements: placements}
+		maj.listener().OnGameOver(maj.GameOver)
+	}
+}
+
+func (maj *Mahjong) checkPlaying() error {
+	if maj.GameOver != nil {
+		return GameIsOver{}
+	}
+	if maj.Result.Done() {
+		return HandOver{}
+	}
+	return nil
+}
+
+//次局の準備
+func (maj *Mahjong) resetHand() {
+	maj.Players.Do(
+		func(player *Player) {
+			*player = Player{FieldWind: player.FieldWind, Score: player.Score, Tiles: make([]Tile, 0)}
+		},
+	)
+	maj.Players.Set(maj.Players.Parent(maj.Round))
+	maj.NextTile = 0
+	maj.Tiles = nil
+	maj.LastTile = Tile{}
+	maj.LastTilePlayer = nil
+	maj.KanCount = 0
+	maj.KanDora = 0
+	maj.PendingKanDora = 0
+	maj.Chankan = nil
+}
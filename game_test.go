@@ -0,0 +1,109 @@
+package mahjong
+
+import "testing"
+
+func newTestGame(t *testing.T) *Mahjong {
+	maj := InitWithSeed(&JapaneseHanChanRule{}, 1)
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return maj
+}
+
+func setScores(maj *Mahjong, scores ...int) {
+	for seat, score := range scores {
+		maj.Players.FindField(FieldWind(seat)).Score = score
+	}
+}
+
+func TestMahjong_Restart(t *testing.T) {
+	maj := newTestGame(t)
+	if err := maj.Restart(); err == nil {
+		t.Error("Restart() during a hand should fail")
+	}
+
+	//子の和了で親が移る
+	maj.Result.AddAgari(&WinResult{Winner: maj.Players.FindField(SouthField)})
+	maj.endHand()
+	if err := maj.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	parent := maj.Players.Parent(maj.Round)
+	if maj.Round.Number != 1 || maj.Round.Honba != 0 || parent.FieldWind != SouthField {
+		t.Errorf("Round = %+v, parent = %v", maj.Round, parent.FieldWind)
+	}
+	if maj.Players.Now() != parent || len(parent.Tiles) != 14 {
+		t.Errorf("parent should start with 14 tiles, has %v", len(parent.Tiles))
+	}
+
+	//親の和了で連荘
+	maj.Result.AddAgari(&WinResult{Winner: parent})
+	maj.endHand()
+	if err := maj.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if maj.Round.Number != 1 || maj.Round.Honba != 1 {
+		t.Errorf("Round = %+v, want renchan", maj.Round)
+	}
+
+	//親が不聴の流局は親流れで積み棒が増える
+	ryuukyoku := &RyuukyokuResult{}
+	ryuukyoku.Tenpai[WestField] = true
+	maj.Result.AddDraw(ryuukyoku)
+	maj.endHand()
+	if err := maj.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if maj.Round.Number != 2 || maj.Round.Honba != 2 {
+		t.Errorf("Round = %+v, want number 2 honba 2", maj.Round)
+	}
+}
+
+func TestMahjong_GameOver(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   int8
+		field    FieldWind
+		scores   []int
+		winner   FieldWind
+		gameOver bool
+	}{
+		{"not all last", 2, SouthField, []int{40000, 20000, 20000, 20000}, WestField, false},
+		{"busted", 0, EastField, []int{41000, 35000, 25000, -1000}, EastField, true},
+		{"all last", 3, SouthField, []int{31000, 29000, 20000, 20000}, EastField, true},
+		{"west entry", 3, SouthField, []int{29000, 29000, 22000, 20000}, EastField, false},
+		{"agari yame", 3, SouthField, []int{20000, 20000, 20000, 40000}, NorthField, true},
+		{"parent renchan", 3, SouthField, []int{40000, 20000, 20000, 20000}, NorthField, false},
+		{"west end", 3, WestField, []int{29000, 29000, 22000, 20000}, EastField, true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj := newTestGame(t)
+				maj.Round.FieldWind = tt.field
+				maj.Round.Number = tt.number
+				setScores(maj, tt.scores...)
+				maj.Result.AddAgari(&WinResult{Winner: maj.Players.FindField(tt.winner)})
+				maj.endHand()
+				if (maj.GameOver != nil) != tt.gameOver {
+					t.Errorf("GameOver = %v, want %v", maj.GameOver != nil, tt.gameOver)
+				}
+				if tt.gameOver && maj.Restart() == nil {
+					t.Error("Restart() after game over should fail")
+				}
+			},
+		)
+	}
+}
+
+func TestMahjong_Placements(t *testing.T) {
+	maj := newTestGame(t)
+	setScores(maj, 25000, 30000, 25000, 20000)
+	placements := maj.Placements()
+	want := []FieldWind{SouthField, EastField, WestField, NorthField}
+	for i, placement := range placements {
+		if placement.Player.FieldWind != want[i] || placement.Rank != i+1 {
+			t.Errorf("Placements()[%v] = %v, want %v", i, placement.Player.FieldWind, want[i])
+		}
+	}
+}
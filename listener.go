@@ -0,0 +1,77 @@
+package mahjong
+
+import (
+	"fmt"
+	"io"
+)
+
+//対局中の出来事を受け取る、表示の仕方は使う側が決める
+type Listener interface {
+	OnDice(dice int)
+	//開門する人
+	OnKaimen(player *Player, dice int)
+	//新しくめくられたドラ表示牌
+	OnDora(indicator Tile)
+	OnRiichi(player *Player, tile Tile)
+	//ダブロンは和了ごとに呼ぶ
+	OnWin(result *WinResult)
+	OnRyuukyoku(result *RyuukyokuResult)
+	OnGameOver(gameOver *GameOver)
+}
+
+//何もしない、Mahjong.Listenerが未設定の時に使う
+type NopListener struct{}
+
+func (NopListener) OnDice(int)                   {}
+func (NopListener) OnKaimen(*Player, int)        {}
+func (NopListener) OnDora(Tile)                  {}
+func (NopListener) OnRiichi(*Player, Tile)       {}
+func (NopListener) OnWin(*WinResult)             {}
+func (NopListener) OnRyuukyoku(*RyuukyokuResult) {}
+func (NopListener) OnGameOver(*GameOver)         {}
+
+//一行ずつ文字で書き出す
+type TextListener struct {
+	Writer io.Writer
+}
+
+func (listener TextListener) OnDice(dice int) {
+	fmt.Fprintln(listener.Writer, "Dice:", dice)
+}
+
+func (listener TextListener) OnKaimen(player *Player, dice int) {
+	fmt.Fprintln(listener.Writer, "プレイヤー", player.FieldWind, "開門、サイコロ", dice)
+}
+
+func (listener TextListener) OnDora(indicator Tile) {
+	fmt.Fprintln(listener.Writer, "ドラ表示牌", TilesName[indicator.TileType])
+}
+
+func (listener TextListener) OnRiichi(player *Player, tile Tile) {
+	fmt.Fprintln(listener.Writer, "プレイヤー", player.FieldWind, "立直", TilesName[tile.TileType])
+}
+
+func (listener TextListener) OnWin(result *WinResult) {
+	name := "ロン"
+	if result.Tsumo {
+		name = "ツモ"
+	}
+	fmt.Fprintln(listener.Writer, "プレイヤー", result.Winner.FieldWind, name, result.Deltas[result.Winner.FieldWind])
+}
+
+func (listener TextListener) OnRyuukyoku(result *RyuukyokuResult) {
+	fmt.Fprintln(listener.Writer, DrawNames[result.Type])
+}
+
+func (listener TextListener) OnGameOver(gameOver *GameOver) {
+	for _, placement := range gameOver.Placements {
+		fmt.Fprintln(listener.Writer, placement.Rank, "プレイヤー", placement.Player.FieldWind, placement.Score, placement.Point)
+	}
+}
+
+func (maj *Mahjong) listener() Listener {
+	if maj.Listener == nil {
+		return NopListener{}
+	}
+	return maj.Listener
+}
@@ -0,0 +1,60 @@
+package mahjong
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type countingListener struct {
+	NopListener
+	dice, kaimen, dora, riichi, wins, ryuukyoku int
+}
+
+func (listener *countingListener) OnDice(int)                   { listener.dice++ }
+func (listener *countingListener) OnKaimen(*Player, int)        { listener.kaimen++ }
+func (listener *countingListener) OnDora(Tile)                  { listener.dora++ }
+func (listener *countingListener) OnRiichi(*Player, Tile)       { listener.riichi++ }
+func (listener *countingListener) OnWin(*WinResult)             { listener.wins++ }
+func (listener *countingListener) OnRyuukyoku(*RyuukyokuResult) { listener.ryuukyoku++ }
+
+func TestMahjong_Listener(t *testing.T) {
+	listener := &countingListener{}
+	maj := InitWithSeed(&JapaneseHanChanRule{}, 1)
+	maj.Listener = listener
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	playHands(t, maj, 3)
+
+	if listener.kaimen != 3 || listener.dice != 6 || listener.dora < 3 {
+		t.Errorf("kaimen = %v, dice = %v, dora = %v", listener.kaimen, listener.dice, listener.dora)
+	}
+	wins := 0
+	for _, event := range maj.Events {
+		switch event.Type {
+		case EventRon, EventTsumo:
+			wins++
+		case EventMultiRon:
+			wins += len(event.Seats)
+		}
+	}
+	if listener.wins != wins || listener.wins+listener.ryuukyoku < 3 {
+		t.Errorf("wins = %v, want %v, ryuukyoku = %v", listener.wins, wins, listener.ryuukyoku)
+	}
+}
+
+func TestTextListener(t *testing.T) {
+	var buf bytes.Buffer
+	maj := InitWithSeed(&JapaneseHanChanRule{}, 1)
+	maj.Listener = TextListener{Writer: &buf}
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	playHands(t, maj, 1)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 5 || !strings.HasPrefix(lines[0], "Dice:") || !strings.HasPrefix(lines[3], "ドラ表示牌") {
+		t.Errorf("output = %q", lines)
+	}
+}
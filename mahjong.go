@@ -2,8 +2,8 @@ package mahjong
 
 import (
 	"errors"
-	"fmt"
 	"math/rand"
+	sort2 "sort"
 	"time"
 )
 
@@ -13,11 +13,25 @@ type Mahjong struct {
 	Players
 	Result
 	Seed           *rand.Rand
+	source         *seedSource
 	NextTile       uint8
 	Tiles          []Tile
 	LastTile       Tile
 	LastTilePlayer *Player
 	KanCount       uint8
+	//めくられた槓ドラと打牌後にめくる槓ドラ
+	KanDora        uint8
+	PendingKanDora uint8
+	Chankan        *Chankan
+	ClaimWindow    *ClaimWindow
+	GameOver       *GameOver
+	//Replayで再現できる操作の記録
+	Events []Event
+	//nilなら何も通知しない
+	Listener Listener
+
+	//供託
+	Deposit int
 }
 
 func Init(rule Rule) *Mahjong {
@@ -26,16 +40,28 @@ func Init(rule Rule) *Mahjong {
 }
 
 func InitWithSeed(rule Rule, seed int64) *Mahjong {
+	source := newSeedSource(seed, 0)
 	maj := &Mahjong{
+		Round:   *rule.MaxRound(),
 		Rule:    rule,
-		Seed:    rand.New(rand.NewSource(seed)),
+		Seed:    rand.New(source),
+		source:  source,
 		Players: rule.PlayersSitDown(),
 	}
+	maj.Round.Seats = int8(maj.Players.Len())
 	maj.Rule.Init(maj)
 	return maj
 }
 
 func (maj *Mahjong) Start() error {
+	if err := maj.start(); err != nil {
+		return err
+	}
+	maj.record(Event{Type: EventStart})
+	return nil
+}
+
+func (maj *Mahjong) start() error {
 	maj.Shuffle()
 	err := maj.Stack()
 	if err != nil {
@@ -58,15 +84,16 @@ func (maj *Mahjong) Stack() error {
 	}
 	dice := maj.Dice() + maj.Dice()
 	kaimenPlayer := maj.Players.Move(dice)
-	maj.Output("プレイヤー", kaimenPlayer.FieldWind, "開門、サイコロ", dice)
-	indexes := JapaneseBaseRule{}.DoraHints(1, false)
-	maj.Output("ドラ", maj.Tiles[indexes[0]].TileType)
+	maj.listener().OnKaimen(kaimenPlayer, dice)
+	maj.notifyDora()
 
 	return nil
 }
 
+//四枚ずつ三周、親は二枚、子は一枚
 func (maj *Mahjong) Haipai() {
-	for i := 0; i < 12; i++ {
+	seats := maj.Players.Len()
+	for i := 0; i < 3*seats; i++ {
 		for j := 0; j < 4; j++ {
 			maj.draw()
 		}
@@ -74,67 +101,228 @@ func (maj *Mahjong) Haipai() {
 	}
 	maj.draw()
 	maj.draw()
-	maj.Players.ToNext()
-	maj.draw()
-	maj.Players.ToNext()
-	maj.draw()
-	maj.Players.ToNext()
-	maj.draw()
+	for i := 1; i < seats; i++ {
+		maj.Players.ToNext()
+		maj.draw()
+	}
 	firstPlayer := maj.Players.ToNext()
 	firstPlayer.Phase = RemoveTile
 	firstPlayer.jun++
 }
 
 func (maj *Mahjong) draw() Tile {
-	maj.Players.Do(
-		func(player *Player) {
-			if _, err := maj.Rule.CanAgari(player, maj.LastTile); err == nil {
-				player.Through = true
-			}
-		})
+	maj.markThrough()
 	tile := maj.Tiles[maj.NextTile]
+	maj.NextTile++
 	player := maj.Players.Now()
+	tile = maj.replaceFlowers(player, tile)
 	player.LastDraw = tile
+	player.rinshan = false
 	player.Tiles = append(player.Tiles, tile)
-	maj.NextTile++
-	if player.Riichi != 0 {
+	//立直後の見逃しはフリテンのまま
+	if player.Riichi.First() {
 		player.Through = false
 	}
 	return tile
 }
 
 func (maj *Mahjong) DrawKan(player *Player) (Tile, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return Tile{}, err
+	}
 	err := player.Phase.Check(AddTileKan)
 	if err != nil {
 		return Tile{}, err
 	}
+	if err := maj.checkClaimWindow(); err != nil {
+		return Tile{}, err
+	}
 	defer player.Phase.Change(RemoveTile)
 
-	if maj.KanCount > 4 {
-		return Tile{}, errors.New("Kanned 4 times. ")
-	}
-	tile := maj.Tiles[uint8(len(maj.Tiles))-maj.KanCount]
+	tile := maj.replaceFlowers(player, maj.Tiles[maj.rinshanIndex()])
 	player.LastDraw = tile
 	player.Tiles = append(player.Tiles, tile)
+	player.rinshan = true
+	maj.record(Event{Type: EventDrawKan, Seats: seats(player), Tiles: []Tile{tile}})
 	return tile, nil
 }
 
+//嶺上牌は王牌の端から槓・抜きドラ・花牌の数だけ、四枚を使い切ったら海底側から王牌へ移った牌
+//王牌のないルールは山の端から順に補充する
+func (maj *Mahjong) rinshanIndex() int {
+	n := len(maj.Tiles)
+	k := maj.replacements() - 1
+	deadWall := int(maj.Rule.WallTilesCannotDraw()) - k - 1
+	if k < 4 || deadWall == 0 {
+		return n - 1 - k
+	}
+	return n - 1 - deadWall - (k - 4)
+}
+
+//嶺上牌から補充した回数
+func (maj *Mahjong) replacements() int {
+	return int(maj.KanCount) + maj.KitaCount() + maj.FlowerCount()
+}
+
+//花牌は脇に置いて補充する、補充する牌がなければ手に残る
+func (maj *Mahjong) replaceFlowers(player *Player, tile Tile) Tile {
+	for tile.IsFlower() && maj.RemainderTilesCanDraw() > 0 {
+		player.Flowers = append(player.Flowers, tile)
+		tile = maj.Tiles[maj.rinshanIndex()]
+	}
+	return tile
+}
+
+func (maj *Mahjong) FlowerCount() int {
+	count := 0
+	maj.Players.Do(
+		func(player *Player) {
+			count += len(player.Flowers)
+		},
+	)
+	return count
+}
+
+//フリテンのないルールのロン、次の人がツモる前か搶槓の間だけ
+func (maj *Mahjong) checkRonTile(player *Player) error {
+	if maj.LastTilePlayer == player || maj.LastTile.TileType == None {
+		return errors.New("No tile to ron. ")
+	}
+	if maj.Chankan == nil && maj.Players.Now().Phase.Check(AddTile) != nil {
+		return errors.New("The discard is no longer available. ")
+	}
+	return nil
+}
+
+//搶槓の受付、加槓の牌は誰でも、暗槓の牌は国士無双のみロンできる
+type Chankan struct {
+	Player *Player
+	Tile
+	Concealed bool
+}
+
+//受付中は鳴けないのでロンだけを受け付け、嶺上牌は解決を待つ
+func (maj *Mahjong) openChankan(player *Player, tile Tile, concealed bool) {
+	maj.Chankan = &Chankan{Player: player, Tile: tile, Concealed: concealed}
+	maj.LastTile = tile
+	maj.LastTilePlayer = player
+	maj.openClaimWindow()
+	if maj.ClaimWindow == nil {
+		maj.Chankan = nil
+		maj.LastTile = Tile{}
+	}
+}
+
+//見逃したら同巡内フリテン
+func (maj *Mahjong) closeChankan() {
+	if maj.Chankan == nil {
+		return
+	}
+	maj.Players.Do(
+		func(player *Player) {
+			if _, err := maj.Rule.CanRon(player); err == nil {
+				player.Through = true
+			}
+		},
+	)
+	maj.Chankan = nil
+	maj.LastTile = Tile{}
+}
+
+//搶槓の受付中と立直後は鳴けない
+func (maj *Mahjong) checkCall(player *Player) error {
+	if maj.Chankan != nil {
+		return errors.New("Waiting for chankan. ")
+	}
+	if !player.Riichi.First() {
+		return errors.New("Riichi player can not call. ")
+	}
+	return nil
+}
+
+//三人麻雀は吃なし
+func (maj *Mahjong) checkChii(player *Player) error {
+	if maj.Players.Len() == 3 {
+		return errors.New("Three players can not chii. ")
+	}
+	return maj.checkCall(player)
+}
+
+//槓は4回まで、海底では不可
+func (maj *Mahjong) canDeclareKan() error {
+	if maj.KanCount >= 4 {
+		return errors.New("Kanned 4 times. ")
+	}
+	if maj.RemainderTilesCanDraw() == 0 {
+		return errors.New("No tile to draw after kan. ")
+	}
+	return nil
+}
+
+//暗槓は即めくり、明槓・加槓は打牌後にめくる
+func (maj *Mahjong) declareKan(concealed bool) {
+	maj.breakIppatsu()
+	maj.revealKanDora()
+	maj.KanCount++
+	if concealed {
+		maj.KanDora++
+		maj.notifyDora()
+	} else {
+		maj.PendingKanDora++
+	}
+}
+
+func (maj *Mahjong) revealKanDora() {
+	if maj.PendingKanDora == 0 {
+		return
+	}
+	maj.KanDora += maj.PendingKanDora
+	maj.PendingKanDora = 0
+	maj.notifyDora()
+}
+
+//ドラのないルールは通知しない
+func (maj *Mahjong) notifyDora() {
+	indicators := maj.Rule.DoraIndicators()
+	if len(indicators) == 0 {
+		return
+	}
+	maj.listener().OnDora(indicators[len(indicators)-1])
+}
+
 func (maj *Mahjong) Draw(player *Player) (Tile, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return Tile{}, err
+	}
+	if err := maj.checkClaimWindow(); err != nil {
+		return Tile{}, err
+	}
 	err := player.Phase.Check(AddTile)
 	if err != nil {
 		return Tile{}, err
 	}
+	if kind, err := maj.Rule.CanRyuukyoku(); err == nil {
+		if kind == DrawExhaustive {
+			return Tile{}, WallExhausted{}
+		}
+		return Tile{}, AbortiveDraw{kind}
+	}
 	defer player.Phase.Change(RemoveTile)
 
-	return maj.draw(), nil
+	tile := maj.draw()
+	maj.record(Event{Type: EventDraw, Seats: seats(player), Tiles: []Tile{tile}})
+	return tile, nil
 }
 
 func (maj *Mahjong) CanDahai(player *Player, tile Tile) (int, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return 0, err
+	}
 	err := player.Phase.Check(RemoveTile)
 	if err != nil {
 		return 0, err
 	}
-	if player.Riichi.First() && tile != player.LastDraw {
+	if !player.Riichi.First() && tile != player.LastDraw {
 		return 0, errors.New("Riichi Player can only discard the last tile. ")
 	}
 	for i, t := range player.Tiles {
@@ -148,11 +336,14 @@ func (maj *Mahjong) CanDahai(player *Player, tile Tile) (int, error) {
 func (maj *Mahjong) dahai(player *Player, i int) {
 	tile := player.Tiles[i]
 	player.Tiles = append(player.Tiles[:i], player.Tiles[i+1:]...)
+	player.ippatsu = false
 	maj.LastTile = tile
 	maj.LastTilePlayer = player
-	discard := DiscardTile{Tile: tile, Jun: maj.Jun(), TsumoGiri: i == 13}
+	discard := DiscardTile{Tile: tile, Jun: maj.Jun(), TsumoGiri: tile == player.LastDraw}
 	player.Discards = append(player.Discards, discard)
+	maj.revealKanDora()
 	maj.toNextPlayer()
+	maj.openClaimWindow()
 }
 
 func (maj *Mahjong) Dahai(player *Player, tile Tile) error {
@@ -162,17 +353,21 @@ func (maj *Mahjong) Dahai(player *Player, tile Tile) error {
 	}
 	maj.dahai(player, i)
 	player.Phase.Change(Idle)
+	maj.record(Event{Type: EventDahai, Seats: seats(player), Tiles: []Tile{tile}})
 	return nil
 }
 
 func (maj *Mahjong) CanChii(player *Player) ([]TilesXY, error) {
+	if err := maj.checkChii(player); err != nil {
+		return nil, err
+	}
 	err := player.Phase.Check(AddTile)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]TilesXY, 0)
-	sortedTiles := SortTiles(player.Tiles)
+	sortedTiles := SortTiles(append([]Tile(nil), player.Tiles...))
 	the3Suits := split3Suits(sortedTiles)
 	for _, suit := range the3Suits {
 		xys := for2Tile(suit)
@@ -188,7 +383,19 @@ func (maj *Mahjong) CanChii(player *Player) ([]TilesXY, error) {
 	return nil, errors.New("Can not chii the tile. ")
 }
 
+//受付への宣言、全員の宣言が揃って優先されれば鳴く
 func (maj *Mahjong) Chii(player *Player, tileA, tileB Tile) error {
+	_, err := maj.Declare(player, Claim{ClaimChii, []Tile{tileA, tileB}})
+	return err
+}
+
+func (maj *Mahjong) chii(player *Player, tileA, tileB Tile) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	if err := maj.checkChii(player); err != nil {
+		return err
+	}
 	if !IsXYZ(tileA.TileType, tileB.TileType, maj.LastTile.TileType) {
 		return errors.New("Can not pon the tile. ")
 	}
@@ -210,12 +417,16 @@ func (maj *Mahjong) Chii(player *Player, tileA, tileB Tile) error {
 	}
 	xyz[0] = maj.LastTile
 	player.XYZs = append(player.XYZs, Sequential{xyz, false})
+	maj.markCalled()
 	maj.LastTile = Tile{}
-
+	maj.record(Event{Type: EventChii, Seats: seats(player), Tiles: []Tile{tileA, tileB}})
 	return nil
 }
 
 func (maj *Mahjong) CanPon(player *Player) ([]TilesXX, error) {
+	if err := maj.checkCall(player); err != nil {
+		return nil, err
+	}
 	if player.HasDiscarded(maj.LastTile) {
 		return nil, errors.New("Discarded. ")
 	}
@@ -237,8 +448,20 @@ func (maj *Mahjong) CanPon(player *Player) ([]TilesXX, error) {
 }
 
 //todo: rotate & move tiles for fuuro
+//受付への宣言、全員の宣言が揃って優先されれば鳴く
 func (maj *Mahjong) Pon(player *Player, tileA, tileB Tile) error {
-	err := player.Phase.Check(Idle)
+	_, err := maj.Declare(player, Claim{ClaimPon, []Tile{tileA, tileB}})
+	return err
+}
+
+func (maj *Mahjong) pon(player *Player, tileA, tileB Tile) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	if err := maj.checkCall(player); err != nil {
+		return err
+	}
+	err := player.Phase.Check(Idle, AddTile)
 	if err != nil {
 		return err
 	}
@@ -263,11 +486,19 @@ func (maj *Mahjong) Pon(player *Player, tileA, tileB Tile) error {
 	}
 	xxx[2] = maj.LastTile
 	player.XXXs = append(player.XXXs, Triplet{xxx, false})
+	maj.markCalled()
 	maj.LastTile = Tile{}
+	maj.record(Event{Type: EventPon, Seats: seats(player), Tiles: []Tile{tileA, tileB}})
 	return nil
 }
 
 func (maj *Mahjong) CanKan(player *Player) (TilesXXX, error) {
+	if err := maj.checkCall(player); err != nil {
+		return TilesXXX{}, err
+	}
+	if err := maj.canDeclareKan(); err != nil {
+		return TilesXXX{}, err
+	}
 	if player.HasDiscarded(maj.LastTile) {
 		return TilesXXX{}, errors.New("Discarded. ")
 	}
@@ -278,12 +509,27 @@ func (maj *Mahjong) CanKan(player *Player) (TilesXXX, error) {
 	return TilesXXX{player.Tiles[indexes[0]], player.Tiles[indexes[1]], player.Tiles[indexes[2]]}, nil
 }
 
+//受付への宣言、全員の宣言が揃って優先されれば大明槓する
 func (maj *Mahjong) Kan(player *Player) error {
+	_, err := maj.Declare(player, Claim{ClaimType: ClaimKan})
+	return err
+}
+
+func (maj *Mahjong) kan(player *Player) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	if err := maj.checkCall(player); err != nil {
+		return err
+	}
 	err := player.Phase.Check(Idle, AddTile)
 	if err != nil {
 		return err
 	}
 
+	if err := maj.canDeclareKan(); err != nil {
+		return err
+	}
 	indexes := player.GetTileTypeIndexes(maj.LastTile.TileType)
 	if len(indexes) < 3 {
 		return errors.New("Can not kan. ")
@@ -298,12 +544,18 @@ func (maj *Mahjong) Kan(player *Player) error {
 	}
 	xxxx.TilesXXXX = maj.LastTile.TileType
 	player.XXXXs = append(player.XXXXs, xxxx)
+	maj.markCalled()
 	maj.LastTile = Tile{}
+	maj.declareKan(false)
+	maj.record(Event{Type: EventKan, Seats: seats(player)})
 	return nil
 }
 
 func (maj *Mahjong) CanAnKan(player *Player) ([]TileType, error) {
-	sorted := SortTiles(player.Tiles)
+	if err := maj.canDeclareKan(); err != nil {
+		return nil, err
+	}
+	sorted := SortTiles(append([]Tile(nil), player.Tiles...))
 	xxxxs := make([]TileType, 0)
 	for i := 0; i < len(sorted)-3; i++ {
 		if IsXXXX(sorted[i].TileType, sorted[i+1].TileType, sorted[i+2].TileType, sorted[i+3].TileType) {
@@ -317,16 +569,23 @@ func (maj *Mahjong) CanAnKan(player *Player) ([]TileType, error) {
 }
 
 func (maj *Mahjong) AnKan(player *Player, tileType TileType) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
 	err := player.Phase.Check(RemoveTile)
 	if err != nil {
 		return err
 	}
 
+	if err := maj.canDeclareKan(); err != nil {
+		return err
+	}
 	indexes := player.GetTileTypeIndexes(tileType)
 
 	if len(indexes) != 4 {
 		return errors.New("Can not kan the tileType. ")
 	}
+	player.Phase.Change(AddTileKan)
 	xxxx := Quad{Concealed: true, Jun: maj.Jun()}
 	for i, index := range indexes {
 		index -= i
@@ -334,10 +593,19 @@ func (maj *Mahjong) AnKan(player *Player, tileType TileType) error {
 	}
 	xxxx.TilesXXXX = tileType
 	player.XXXXs = append(player.XXXXs, xxxx)
+	maj.declareKan(true)
+	maj.openChankan(player, Tile{TileType: tileType}, true)
+	maj.record(Event{Type: EventAnKan, Seats: seats(player), TileType: tileType})
 	return nil
 }
 
 func (maj *Mahjong) CanKaKan(player *Player) ([]Tile, error) {
+	if err := player.Phase.Check(RemoveTile); err != nil {
+		return nil, err
+	}
+	if err := maj.canDeclareKan(); err != nil {
+		return nil, err
+	}
 	options := make([]Tile, 0)
 	for _, xxx := range player.XXXs {
 		for _, tile := range player.Tiles {
@@ -353,16 +621,92 @@ func (maj *Mahjong) CanKaKan(player *Player) ([]Tile, error) {
 }
 
 func (maj *Mahjong) KaKan(player *Player, tile Tile) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	err := player.Phase.Check(RemoveTile)
+	if err != nil {
+		return err
+	}
+	if err := maj.canDeclareKan(); err != nil {
+		return err
+	}
+	indexes, err := player.GetTilesIndexes(tile)
+	if err != nil {
+		return err
+	}
 	for i, xxx := range player.XXXs {
 		if xxx.TilesXXX[0].TileType == tile.TileType {
+			player.Tiles = append(player.Tiles[:indexes[0]], player.Tiles[indexes[0]+1:]...)
 			player.XXXs = append(player.XXXs[:i], player.XXXs[i+1:]...)
 			player.XXXXs = append(player.XXXXs, Quad{tile.TileType, false, maj.Jun()})
+			player.Phase.Change(AddTileKan)
+			maj.declareKan(false)
+			maj.openChankan(player, tile, false)
+			maj.record(Event{Type: EventKaKan, Seats: seats(player), Tiles: []Tile{tile}})
 			return nil
 		}
 	}
 	return errors.New("Can not kakan. ")
 }
 
+//三人麻雀の抜きドラ、立直後はツモった北だけ
+func (maj *Mahjong) CanKita(player *Player) ([]Tile, error) {
+	if maj.Players.Len() != 3 {
+		return nil, errors.New("Kita is only for three players. ")
+	}
+	if err := player.Phase.Check(RemoveTile); err != nil {
+		return nil, err
+	}
+	if maj.RemainderTilesCanDraw() == 0 {
+		return nil, errors.New("No tile to draw after kita. ")
+	}
+	options := make([]Tile, 0)
+	for _, tile := range player.Tiles {
+		if tile.TileType == North && (player.Riichi.First() || tile == player.LastDraw) {
+			options = append(options, tile)
+		}
+	}
+	if len(options) == 0 {
+		return nil, errors.New("Has no north to kita. ")
+	}
+	return options, nil
+}
+
+//抜いた北は嶺上牌で補う
+func (maj *Mahjong) Kita(player *Player, tile Tile) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	options, err := maj.CanKita(player)
+	if err != nil {
+		return err
+	}
+	for _, option := range options {
+		if option != tile {
+			continue
+		}
+		indexes, _ := player.GetTilesIndexes(tile)
+		player.Tiles = append(player.Tiles[:indexes[0]], player.Tiles[indexes[0]+1:]...)
+		player.Kita = append(player.Kita, tile)
+		player.Phase.Change(AddTileKan)
+		maj.record(Event{Type: EventKita, Seats: seats(player), Tiles: []Tile{tile}})
+		return nil
+	}
+	return errors.New("Can not kita the tile. ")
+}
+
+//抜きドラの枚数
+func (maj *Mahjong) KitaCount() int {
+	count := 0
+	maj.Players.Do(
+		func(player *Player) {
+			count += len(player.Kita)
+		},
+	)
+	return count
+}
+
 func (maj *Mahjong) CanRiichi(player *Player) ([]Tile, error) {
 	err := player.Phase.Check(RemoveTile)
 	if err != nil {
@@ -371,7 +715,18 @@ func (maj *Mahjong) CanRiichi(player *Player) ([]Tile, error) {
 	return maj.Rule.CanRiichi(player)
 }
 
+func (maj *Mahjong) CanOpenRiichi(player *Player) ([]Tile, error) {
+	err := player.Phase.Check(RemoveTile)
+	if err != nil {
+		return nil, err
+	}
+	return maj.Rule.CanOpenRiichi(player)
+}
+
 func (maj *Mahjong) Riichi(player *Player, tile Tile) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
 	err := player.Phase.Check(RemoveTile)
 	if err != nil {
 		return err
@@ -379,27 +734,120 @@ func (maj *Mahjong) Riichi(player *Player, tile Tile) error {
 	err = maj.Rule.Riichi(player, tile)
 	if err == nil {
 		player.Phase.Change(Idle)
+		maj.listener().OnRiichi(player, tile)
+		maj.record(Event{Type: EventRiichi, Seats: seats(player), Tiles: []Tile{tile}})
+	}
+	return err
+}
+
+func (maj *Mahjong) OpenRiichi(player *Player, tile Tile) error {
+	if err := maj.checkPlaying(); err != nil {
+		return err
+	}
+	err := player.Phase.Check(RemoveTile)
+	if err != nil {
+		return err
+	}
+	err = maj.Rule.OpenRiichi(player, tile)
+	if err == nil {
+		player.Phase.Change(Idle)
+		maj.listener().OnRiichi(player, tile)
+		maj.record(Event{Type: EventOpenRiichi, Seats: seats(player), Tiles: []Tile{tile}})
 	}
 	return err
 }
 
 func (maj *Mahjong) CanRon(player *Player) ([]Agari, error) {
-	err := player.Phase.Check(Idle)
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
+	err := player.Phase.Check(Idle, AddTile)
 	if err != nil {
 		return nil, err
 	}
 	return maj.Rule.CanRon(player)
 }
 
-func (maj *Mahjong) Ron(player *Player) error {
-	err := player.Phase.Check(Idle)
+//受付中は頭ハネを守るためDeclareでロンする
+func (maj *Mahjong) Ron(player *Player) (*WinResult, error) {
+	if err := maj.checkClaimWindow(); err != nil {
+		return nil, err
+	}
+	return maj.ron(player)
+}
+
+func (maj *Mahjong) ron(player *Player) (*WinResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
+	err := player.Phase.Check(Idle, AddTile)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	result, err := maj.Rule.Ron(player)
+	if err != nil {
+		return nil, err
+	}
+	maj.Result.AddAgari(result)
+	maj.endHand()
+	maj.record(Event{Type: EventRon, Seats: seats(player)})
+	return result, nil
+}
+
+//ダブロン、放銃者の下家から順に和了する
+func (maj *Mahjong) MultiRon(players ...*Player) ([]*WinResult, error) {
+	if err := maj.checkClaimWindow(); err != nil {
+		return nil, err
 	}
-	return maj.Rule.Ron(player)
+	return maj.multiRon(players...)
+}
+
+func (maj *Mahjong) multiRon(players ...*Player) ([]*WinResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
+	for _, player := range players {
+		if err := player.Phase.Check(Idle, AddTile); err != nil {
+			return nil, err
+		}
+	}
+	if err := maj.Rule.CanMultiRon(players); err != nil {
+		return nil, err
+	}
+	results := make([]*WinResult, 0)
+	for _, player := range maj.ronOrder(players) {
+		result, err := maj.Rule.Ron(player)
+		if err != nil {
+			return nil, err
+		}
+		maj.Result.AddAgari(result)
+		results = append(results, result)
+	}
+	maj.endHand()
+	maj.record(Event{Type: EventMultiRon, Seats: seats(players...)})
+	return results, nil
+}
+
+func (maj *Mahjong) ronOrder(players []*Player) []*Player {
+	discarder := maj.LastTilePlayer
+	sorted := make([]*Player, len(players))
+	copy(sorted, players)
+	distance := func(player *Player) int {
+		seats := maj.Players.Len()
+		return (int(player.FieldWind) - int(discarder.FieldWind) + seats) % seats
+	}
+	sort2.Slice(
+		sorted, func(i, j int) bool {
+			return distance(sorted[i]) < distance(sorted[j])
+		},
+	)
+	return sorted
 }
 
 func (maj *Mahjong) CanTsumo(player *Player) ([]Agari, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
 	err := player.Phase.Check(RemoveTile)
 	if err != nil {
 		return nil, err
@@ -407,12 +855,22 @@ func (maj *Mahjong) CanTsumo(player *Player) ([]Agari, error) {
 	return maj.Rule.CanTsumo(player)
 }
 
-func (maj *Mahjong) Tsumo(player *Player) error {
+func (maj *Mahjong) Tsumo(player *Player) (*WinResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
 	err := player.Phase.Check(RemoveTile)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	result, err := maj.Rule.Tsumo(player)
+	if err != nil {
+		return nil, err
 	}
-	return maj.Rule.Tsumo(player)
+	maj.Result.AddAgari(result)
+	maj.endHand()
+	maj.record(Event{Type: EventTsumo, Seats: seats(player)})
+	return result, nil
 }
 
 func (maj *Mahjong) CanNineYaochus(player *Player) error {
@@ -422,36 +880,104 @@ func (maj *Mahjong) CanNineYaochus(player *Player) error {
 	return maj.Rule.CanNineYaochus(player)
 }
 
-func (maj *Mahjong) NineYaochus(player *Player) error {
+func (maj *Mahjong) NineYaochus(player *Player) (*RyuukyokuResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
 	if !maj.IsTurn(player) {
-		return NotTurn{}
+		return nil, NotTurn{}
+	}
+	if err := player.Phase.Check(RemoveTile); err != nil {
+		return nil, err
+	}
+	result, err := maj.Rule.NineYaochus(player)
+	if err != nil {
+		return nil, err
+	}
+	maj.record(Event{Type: EventNineYaochus, Seats: seats(player)})
+	return maj.drawn(result), nil
+}
+
+func (maj *Mahjong) TripleRon(players ...*Player) (*RyuukyokuResult, error) {
+	if err := maj.checkClaimWindow(); err != nil {
+		return nil, err
+	}
+	return maj.tripleRon(players...)
+}
+
+func (maj *Mahjong) tripleRon(players ...*Player) (*RyuukyokuResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
+	for _, player := range players {
+		if err := player.Phase.Check(Idle, AddTile); err != nil {
+			return nil, err
+		}
+	}
+	result, err := maj.Rule.TripleRon(players)
+	if err != nil {
+		return nil, err
 	}
-	return maj.Rule.NineYaochus(player)
+	maj.record(Event{Type: EventTripleRon, Seats: seats(players...)})
+	return maj.drawn(result), nil
+}
+
+func (maj *Mahjong) CanRyuukyoku() (DrawType, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return 0, err
+	}
+	return maj.Rule.CanRyuukyoku()
+}
+
+//流局
+func (maj *Mahjong) Ryuukyoku() (*RyuukyokuResult, error) {
+	if err := maj.checkPlaying(); err != nil {
+		return nil, err
+	}
+	result, err := maj.Rule.Ryuukyoku()
+	if err != nil {
+		return nil, err
+	}
+	maj.record(Event{Type: EventRyuukyoku})
+	return maj.drawn(result), nil
+}
+
+func (maj *Mahjong) drawn(result *RyuukyokuResult) *RyuukyokuResult {
+	maj.Result.AddDraw(result)
+	maj.endHand()
+	return result
 }
 
 func (maj *Mahjong) CanRestart() error {
-	if maj.Result.Done() {
+	if maj.GameOver != nil {
+		return GameIsOver{}
+	}
+	if !maj.Result.Done() {
 		return errors.New("gaming")
 	}
 	return nil
 }
 
+//次局へ
 func (maj *Mahjong) Restart() error {
 	err := maj.CanRestart()
 	if err != nil {
 		return err
 	}
-	err = maj.Start()
-	if err != nil {
+	renchan := maj.Result.Renchan
+	maj.Round.ToNext(renchan, renchan || maj.Result.ResultType == DrawResult)
+	maj.Result.Init()
+	maj.resetHand()
+	if err := maj.start(); err != nil {
 		return err
 	}
-	maj.Result.Init()
+	maj.record(Event{Type: EventRestart})
 	return nil
 }
 
 func (maj *Mahjong) Dice() int {
 	dice := maj.Seed.Intn(6) + 1
-	maj.Output("Dice:", dice)
+	maj.listener().OnDice(dice)
 	return dice
 }
 
@@ -471,8 +997,26 @@ func (maj *Mahjong) Jun() Jun {
 	return max
 }
 
+//鳴きの有無
+func (maj *Mahjong) HasCalled() bool {
+	called := false
+	maj.Players.Do(
+		func(player *Player) {
+			for _, xxxx := range player.XXXXs {
+				if !xxxx.Concealed {
+					called = true
+				}
+			}
+			if len(player.XXXs) != 0 || len(player.XYZs) != 0 {
+				called = true
+			}
+		},
+	)
+	return called
+}
+
 func (maj *Mahjong) Dora() uint8 {
-	return maj.KanCount + 1
+	return maj.KanDora + 1
 }
 
 func (maj *Mahjong) RemainderTilesAll() uint8 {
@@ -483,10 +1027,46 @@ func (maj *Mahjong) RemainderTilesCanDraw() uint8 {
 	return maj.RemainderTilesAll() - maj.Rule.WallTilesCannotDraw()
 }
 
+//鳴かれた捨て牌に印を付ける、ロンを見逃した人はフリテン
+func (maj *Mahjong) markCalled() {
+	maj.markThrough()
+	discarder := maj.LastTilePlayer
+	if discarder == nil || len(discarder.Discards) == 0 {
+		return
+	}
+	discarder.Discards[len(discarder.Discards)-1].Called = true
+	maj.breakIppatsu()
+}
+
+//捨て牌で和了できるのに見逃した人は同巡内フリテン
+func (maj *Mahjong) markThrough() {
+	if maj.LastTile.TileType == None {
+		return
+	}
+	maj.Players.Do(
+		func(player *Player) {
+			if _, err := maj.Rule.CanAgari(player, maj.LastTile); err == nil {
+				player.Through = true
+			}
+		},
+	)
+}
+
+//鳴きが入ると一発は消える
+func (maj *Mahjong) breakIppatsu() {
+	maj.Players.Do(
+		func(player *Player) {
+			player.ippatsu = false
+		},
+	)
+}
+
 func (maj *Mahjong) playerTakeTurn(player *Player) {
 	maj.Players.Now().Phase.Change(Idle)
 	maj.Players.Set(player)
 	maj.Players.Right(maj.LastTilePlayer).Phase.Change(Idle)
+	//鳴いた後はツモ牌がない
+	player.LastDraw = Tile{}
 	player.jun++
 }
 
@@ -499,6 +1079,7 @@ func (maj *Mahjong) toNextPlayer() *Player {
 
 type PlayerActions struct {
 	Draw         bool
+	DrawKan      bool
 	Dahai        bool
 	Chii         bool
 	ChiiOption   []TilesXY
@@ -510,11 +1091,15 @@ type PlayerActions struct {
 	AnKanOption  []TileType
 	KaKan        bool
 	KaKanOption  []Tile
+	Kita         bool
+	KitaOption   []Tile
 	Riichi       bool
 	RiichiOption []Tile
+	OpenRiichi   bool
 	Ron          bool
 	Tsumo        bool
 	NineYaochus  bool
+	Ryuukyoku    bool
 }
 
 func (maj *Mahjong) PlayerCan(player *Player) *PlayerActions {
@@ -524,9 +1109,12 @@ func (maj *Mahjong) PlayerCan(player *Player) *PlayerActions {
 
 	pa := new(PlayerActions)
 
-	if player.Phase.Check(AddTile) == nil {
+	if player.Phase.Check(AddTile) == nil && maj.RemainderTilesCanDraw() != 0 {
 		pa.Draw = true
 	}
+	if player.Phase.Check(AddTileKan) == nil {
+		pa.DrawKan = true
+	}
 	if player.Phase.Check(RemoveTile) == nil {
 		pa.Dahai = true
 	}
@@ -550,10 +1138,17 @@ func (maj *Mahjong) PlayerCan(player *Player) *PlayerActions {
 		pa.KaKan = true
 		pa.KaKanOption = option
 	}
+	if option, err := maj.CanKita(player); err == nil {
+		pa.Kita = true
+		pa.KitaOption = option
+	}
 	if option, err := maj.CanRiichi(player); err == nil {
 		pa.Riichi = true
 		pa.RiichiOption = option
 	}
+	if _, err := maj.CanOpenRiichi(player); err == nil {
+		pa.OpenRiichi = true
+	}
 	if _, err := maj.CanRon(player); err == nil {
 		pa.Ron = true
 	}
@@ -563,10 +1158,9 @@ func (maj *Mahjong) PlayerCan(player *Player) *PlayerActions {
 	if maj.CanNineYaochus(player) == nil {
 		pa.NineYaochus = true
 	}
+	if _, err := maj.CanRyuukyoku(); err == nil && maj.IsTurn(player) {
+		pa.Ryuukyoku = true
+	}
 
 	return pa
 }
-
-func (maj *Mahjong) Output(v ...interface{}) {
-	fmt.Println(v...)
-}
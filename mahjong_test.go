@@ -0,0 +1,227 @@
+package mahjong
+
+import "testing"
+
+func TestMahjong_AnKan(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	for i := 0; i < 4; i++ {
+		parent.Tiles[i] = Tile{TileType: East, Id: int8(i)}
+	}
+	remainder := maj.RemainderTilesCanDraw()
+	if err := maj.AnKan(parent, East); err != nil {
+		t.Fatal(err)
+	}
+	if maj.KanCount != 1 || maj.KanDora != 1 || len(maj.Rule.DoraIndicators()) != 2 {
+		t.Errorf("KanCount = %v, KanDora = %v, want 1, 1", maj.KanCount, maj.KanDora)
+	}
+	if maj.RemainderTilesCanDraw() != remainder-1 {
+		t.Errorf("RemainderTilesCanDraw() = %v, want %v", maj.RemainderTilesCanDraw(), remainder-1)
+	}
+	if _, err := maj.Draw(parent); err == nil {
+		t.Error("Draw() after ankan should fail")
+	}
+	tile, err := maj.DrawKan(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tile != maj.Tiles[len(maj.Tiles)-1] || parent.Phase != RemoveTile {
+		t.Errorf("DrawKan() = %v, phase = %v", tile, parent.Phase.Name())
+	}
+}
+
+func TestMahjong_Kan_DoraTiming(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	if err := maj.Dahai(parent, parent.Tiles[0]); err != nil {
+		t.Fatal(err)
+	}
+	maj.closeClaimWindow()
+	maj.LastTile = Tile{TileType: Red, Id: 3}
+	child := maj.Players.FindField(SouthField)
+	for i := 0; i < 3; i++ {
+		child.Tiles[i] = Tile{TileType: Red, Id: int8(i)}
+	}
+	if err := maj.kan(child); err != nil {
+		t.Fatal(err)
+	}
+	if maj.KanDora != 0 || maj.PendingKanDora != 1 {
+		t.Errorf("KanDora = %v, pending = %v, want 0, 1", maj.KanDora, maj.PendingKanDora)
+	}
+	if _, err := maj.DrawKan(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := maj.Dahai(child, child.LastDraw); err != nil {
+		t.Fatal(err)
+	}
+	if maj.KanDora != 1 || maj.PendingKanDora != 0 {
+		t.Errorf("KanDora = %v, pending = %v, want 1, 0", maj.KanDora, maj.PendingKanDora)
+	}
+}
+
+func TestMahjong_KaKan(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	parent.XXXs = []Triplet{{TilesXXX: TilesXXX{{TileType: West}, {TileType: West, Id: 1}, {TileType: West, Id: 2}}}}
+	parent.Tiles[0] = Tile{TileType: West, Id: 3}
+	count := len(parent.Tiles)
+
+	parent.Phase.Change(Idle)
+	if err := maj.KaKan(parent, parent.Tiles[0]); err == nil {
+		t.Error("KaKan() out of turn should fail")
+	}
+	parent.Phase.Change(RemoveTile)
+	if err := maj.KaKan(parent, Tile{TileType: West, Id: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if len(parent.Tiles) != count-1 || len(parent.XXXs) != 0 || len(parent.XXXXs) != 1 {
+		t.Errorf("KaKan() tiles = %v, melds = %v/%v", len(parent.Tiles), parent.XXXs, parent.XXXXs)
+	}
+	if parent.Phase != AddTileKan || maj.KanCount != 1 || maj.PendingKanDora != 1 {
+		t.Errorf("KaKan() phase = %v, kan = %v", parent.Phase.Name(), maj.KanCount)
+	}
+}
+
+func TestMahjong_CanAnKan_Limit(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	for i := 0; i < 4; i++ {
+		parent.Tiles[i] = Tile{TileType: North, Id: int8(i)}
+	}
+	maj.KanCount = 4
+	if _, err := maj.CanAnKan(parent); err == nil {
+		t.Error("CanAnKan() after 4 kans should fail")
+	}
+	maj.KanCount = 0
+	if _, err := maj.CanAnKan(parent); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMahjong_KaKan_Chankan(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	parent.XXXs = []Triplet{{TilesXXX: TilesXXX{{TileType: Dots5}, {TileType: Dots5, Id: 1}, {TileType: Dots5, Id: 2}}}}
+	parent.Tiles[0] = Tile{TileType: Dots5, Id: 3}
+	south := maj.Players.FindField(SouthField)
+	south.Tiles = toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+			Dots2, Dots2, Dots4, Dots6,
+		},
+	)
+	west := maj.Players.FindField(WestField)
+	west.Tiles[0] = Tile{TileType: Dots6}
+	west.Tiles[1] = Tile{TileType: Dots7}
+
+	if err := maj.KaKan(parent, Tile{TileType: Dots5, Id: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if maj.ClaimWindow == nil || !maj.ClaimWindow.CanClaim(south, ClaimRon) || maj.ClaimWindow.CanClaim(west, ClaimPass) {
+		t.Fatalf("ClaimWindow = %+v, want ron by south only", maj.ClaimWindow)
+	}
+	if _, err := maj.DrawKan(parent); err == nil {
+		t.Error("DrawKan() before the chankan claims should fail")
+	}
+	claimed, err := maj.Declare(south, Claim{ClaimType: ClaimRon})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := claimed.Wins[0]
+	robbed := false
+	for _, yaku := range result.YakuTachi {
+		if yaku.Name == "搶槓" {
+			robbed = true
+		}
+	}
+	if !robbed || result.Discarder != parent {
+		t.Errorf("Ron() = %+v, want 搶槓 from the parent", result)
+	}
+}
+
+func TestMahjong_AnKan_Chankan(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	parent.Tiles = toSampleTiles(
+		[]TileType{
+			North, North, North, North, Dots2, Dots3, Dots4, Bamboo6, Bamboo7, Bamboo8, Characters2, Characters3,
+			Characters4, Dots9,
+		},
+	)
+	south := maj.Players.FindField(SouthField)
+	south.Tiles = toSampleTiles(
+		[]TileType{
+			Dots1, Dots9, Bamboo1, Bamboo9, Characters1, Characters9, East, South, West, White, Green, Red, Dots1,
+		},
+	)
+	west := maj.Players.FindField(WestField)
+	west.Tiles = toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+			Red, Red, Red, North,
+		},
+	)
+
+	if err := maj.AnKan(parent, North); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := maj.CanRon(west); err == nil {
+		t.Error("CanRon() on an ankan without kokushi should fail")
+	}
+	if maj.ClaimWindow == nil || !maj.ClaimWindow.CanClaim(south, ClaimRon) || maj.ClaimWindow.CanClaim(west, ClaimPass) {
+		t.Fatalf("ClaimWindow = %+v, want ron by south only", maj.ClaimWindow)
+	}
+	//見逃すまで嶺上牌は引けない
+	if _, err := maj.DrawKan(parent); err == nil {
+		t.Error("DrawKan() before the chankan claims should fail")
+	}
+	if _, err := maj.Declare(south, Claim{ClaimType: ClaimPass}); err != nil {
+		t.Fatal(err)
+	}
+	if maj.Chankan != nil || !south.Through {
+		t.Errorf("Declare() chankan = %v, through = %v", maj.Chankan, south.Through)
+	}
+	if _, err := maj.DrawKan(parent); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//副露があってもツモった牌ならツモ切り、鳴いた後はツモ切りにならない
+func TestMahjong_Dahai_TsumoGiri(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	hand, err := ParseHand("1234m456p789s11z c123p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.SetHand(hand)
+	south := maj.Players.FindField(SouthField)
+	//南はツモ牌を持ったまま鳴く
+	hand, err = ParseHand("11m456p789s11z234m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	south.SetHand(hand)
+	south.LastDraw = south.Tiles[len(south.Tiles)-1]
+	parent.LastDraw = parent.Tiles[0]
+	if err := maj.Dahai(parent, parent.LastDraw); err != nil {
+		t.Fatal(err)
+	}
+	if discards := parent.Discards; !discards[len(discards)-1].TsumoGiri {
+		t.Errorf("Discards = %+v, want tsumogiri", discards)
+	}
+
+	//宣言できるのは南だけなのでそのまま解決する
+	if _, err := maj.Declare(south, Claim{ClaimPon, south.Tiles[:2]}); err != nil {
+		t.Fatal(err)
+	}
+	if south.LastDraw != (Tile{}) {
+		t.Errorf("LastDraw after pon = %+v", south.LastDraw)
+	}
+	if err := maj.Dahai(south, south.Tiles[len(south.Tiles)-1]); err != nil {
+		t.Fatal(err)
+	}
+	if discards := south.Discards; discards[len(discards)-1].TsumoGiri {
+		t.Errorf("Discards = %+v, want no tsumogiri after pon", discards)
+	}
+}
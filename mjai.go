@@ -0,0 +1,203 @@
+package mahjong
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+//mjaiのイベント、使う項目だけ
+type mjaiEvent struct {
+	Type           string     `json:"type"`
+	Actor          int        `json:"actor"`
+	Target         int        `json:"target"`
+	Pai            string     `json:"pai"`
+	Consumed       []string   `json:"consumed"`
+	Bakaze         string     `json:"bakaze"`
+	Kyoku          int        `json:"kyoku"`
+	Honba          int        `json:"honba"`
+	Kyotaku        int        `json:"kyotaku"`
+	Scores         []int      `json:"scores"`
+	Tehais         [][]string `json:"tehais"`
+	DoraMarker     string     `json:"dora_marker"`
+	UraDoraMarkers []string   `json:"uradora_markers"`
+	Deltas         []int      `json:"deltas"`
+	Reason         string     `json:"reason"`
+}
+
+var mjaiHonors = map[string]TileType{
+	"E": East, "S": South, "W": West, "N": North, "P": White, "F": Green, "C": Red,
+}
+
+//1m-9m 1p-9p 1s-9s、赤五は5mr、字牌はESWNPFC
+func mjaiTile(pai string) (Tile, error) {
+	if tileType, ok := mjaiHonors[pai]; ok {
+		return logTile(tileType), nil
+	}
+	if pai == "5mr" || pai == "5pr" || pai == "5sr" {
+		tile, err := ParseTile("0" + pai[1:2])
+		return Tile{TileType: tile.TileType}, err
+	}
+	if len(pai) != 2 || pai[0] < '1' || pai[0] > '9' || pai[1] == 'z' {
+		return Tile{}, errors.New("Invalid mjai tile " + pai + ". ")
+	}
+	tile, err := ParseTile(pai)
+	if err != nil {
+		return Tile{}, err
+	}
+	return logTile(tile.TileType), nil
+}
+
+func mjaiTiles(pais []string) ([]Tile, error) {
+	tiles := make([]Tile, 0, len(pais))
+	for _, pai := range pais {
+		tile, err := mjaiTile(pai)
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles, nil
+}
+
+//一行一イベントのmjaiログ
+func ParseMjai(reader io.Reader) (*GameLog, error) {
+	decoder := json.NewDecoder(reader)
+	log := &GameLog{}
+	var hand *HandLog
+	riichi := false
+	kan := false
+	lastDraw := 0
+	for {
+		var event mjaiEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if event.Type != "start_kyoku" && hand == nil {
+			continue
+		}
+		seat := FieldWind(event.Actor)
+		var err error
+		switch event.Type {
+		case "start_kyoku":
+			hand, err = newMjaiHand(event)
+			riichi, kan = false, false
+		case "tsumo":
+			action := LogAction{Type: LogDraw, Seat: seat}
+			if kan {
+				action.Type = LogDrawKan
+				kan = false
+			}
+			action.Tile, err = mjaiTile(event.Pai)
+			lastDraw = event.Actor
+			hand.Actions = append(hand.Actions, action)
+		case "reach":
+			riichi = true
+		case "dahai":
+			action := LogAction{Type: LogDiscard, Seat: seat}
+			if riichi {
+				action.Type = LogRiichi
+				riichi = false
+			}
+			action.Tile, err = mjaiTile(event.Pai)
+			hand.Actions = append(hand.Actions, action)
+		case "chi", "pon":
+			action := LogAction{Type: LogChii, Seat: seat}
+			if event.Type == "pon" {
+				action.Type = LogPon
+			}
+			if action.Tile, err = mjaiTile(event.Pai); err == nil {
+				action.Consumed, err = mjaiTiles(event.Consumed)
+			}
+			hand.Actions = append(hand.Actions, action)
+		case "daiminkan", "ankan", "kakan":
+			action := LogAction{Type: LogKan, Seat: seat}
+			pai := event.Pai
+			switch event.Type {
+			case "ankan":
+				action.Type = LogAnKan
+				if len(event.Consumed) == 0 {
+					return nil, errors.New("Ankan without tiles. ")
+				}
+				pai = event.Consumed[0]
+			case "kakan":
+				action.Type = LogKaKan
+			}
+			action.Tile, err = mjaiTile(pai)
+			hand.Actions = append(hand.Actions, action)
+			kan = true
+		case "dora":
+			var tile Tile
+			tile, err = mjaiTile(event.DoraMarker)
+			hand.DoraIndicators = append(hand.DoraIndicators, tile)
+		case "hora":
+			action := LogAction{Type: LogRon, Seat: seat}
+			if event.Actor == event.Target {
+				action.Type = LogTsumo
+			}
+			hand.Actions = append(hand.Actions, action)
+			if len(event.UraDoraMarkers) != 0 {
+				hand.UraDoraIndicators, err = mjaiTiles(event.UraDoraMarkers)
+			}
+			err = hand.addMjaiDeltas(event.Deltas, err)
+		case "ryukyoku":
+			switch event.Reason {
+			case "kyushukyuhai":
+				hand.Actions = append(hand.Actions, LogAction{Type: LogNineYaochus, Seat: FieldWind(lastDraw)})
+			case "sanchaho":
+				hand.Actions = append(hand.Actions, LogAction{Type: LogTripleRon})
+			default:
+				hand.Actions = append(hand.Actions, LogAction{Type: LogRyuukyoku})
+			}
+			err = hand.addMjaiDeltas(event.Deltas, nil)
+		case "end_kyoku":
+			log.Hands = append(log.Hands, *hand)
+			hand = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return log, nil
+}
+
+func newMjaiHand(event mjaiEvent) (*HandLog, error) {
+	winds := map[string]FieldWind{"E": EastField, "S": SouthField, "W": WestField, "N": NorthField}
+	wind, ok := winds[event.Bakaze]
+	if !ok || len(event.Tehais) != 4 || len(event.Scores) != 4 {
+		return nil, errors.New("Invalid mjai start_kyoku. ")
+	}
+	hand := &HandLog{
+		Round:   Round{FieldWind: wind, Number: int8(event.Kyoku - 1), Honba: int8(event.Honba)},
+		Deposit: event.Kyotaku * RiichiDeposit,
+	}
+	copy(hand.Scores[:], event.Scores)
+	var err error
+	for seat, tehai := range event.Tehais {
+		if hand.Haipai[seat], err = mjaiTiles(tehai); err != nil {
+			return nil, err
+		}
+	}
+	dora, err := mjaiTile(event.DoraMarker)
+	if err != nil {
+		return nil, err
+	}
+	hand.DoraIndicators = []Tile{dora}
+	return hand, nil
+}
+
+//ダブロンは和了ごとに点数移動が来る
+func (hand *HandLog) addMjaiDeltas(deltas []int, err error) error {
+	if err != nil {
+		return err
+	}
+	if len(deltas) != 4 {
+		return errors.New("Invalid mjai deltas. ")
+	}
+	for seat, delta := range deltas {
+		hand.Deltas[seat] += delta
+	}
+	return nil
+}
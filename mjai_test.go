@@ -0,0 +1,59 @@
+package mahjong
+
+import (
+	"strings"
+	"testing"
+)
+
+//東1局 南家のチーから西家が8mでロン
+const mjaiSample = `{"type":"start_game","names":["A","B","C","D"]}
+{"type":"start_kyoku","bakaze":"E","dora_marker":"C","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"scores":[25000,25000,25000,25000],"tehais":[["1m","9m","1p","9p","1s","3s","9s","E","S","W","N","P","F"],["4s","5s","8m","1m","9m","1p","9p","1s","9s","E","S","W","N"],["2p","3p","4p","5p","6p","7p","2s","3s","4s","6m","6m","6m","7m"],["1m","9m","1p","9p","1s","9s","E","S","W","N","P","F","C"]]}
+{"type":"tsumo","actor":0,"pai":"C"}
+{"type":"dahai","actor":0,"pai":"3s","tsumogiri":false}
+{"type":"chi","actor":1,"target":0,"pai":"3s","consumed":["4s","5s"]}
+{"type":"dahai","actor":1,"pai":"8m","tsumogiri":false}
+{"type":"hora","actor":2,"target":1,"pai":"8m","uradora_markers":[],"fu":30,"fan":2,"hora_points":2000,"deltas":[0,-2000,2000,0],"scores":[25000,23000,27000,25000]}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+
+func TestParseMjai(t *testing.T) {
+	log, err := ParseMjai(strings.NewReader(mjaiSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Hands) != 1 {
+		t.Fatalf("len(Hands) = %v", len(log.Hands))
+	}
+	hand := log.Hands[0]
+	if hand.Deltas != [4]int{0, -2000, 2000, 0} || len(hand.Actions) != 5 || hand.Actions[4].Type != LogRon {
+		t.Errorf("Hands[0] = %+v", hand)
+	}
+	rule := &JapaneseHanChanRule{JapaneseBaseRule{Options: &TenhouRuleOptions}}
+	if report := ReplayLog(rule, log); len(report.Divergences) != 0 {
+		t.Errorf("ReplayLog() = %+v", report)
+	}
+}
+
+func TestMjaiTile(t *testing.T) {
+	tests := []struct {
+		pai  string
+		want TileType
+		red  bool
+	}{
+		{"1m", Characters1, false},
+		{"5pr", Dots5, true},
+		{"9s", Bamboo9, false},
+		{"P", White, false},
+		{"C", Red, false},
+	}
+	for _, tt := range tests {
+		got, err := mjaiTile(tt.pai)
+		if err != nil || got.TileType != tt.want || got.IsRed() != tt.red {
+			t.Errorf("mjaiTile(%v) = %+v, %v", tt.pai, got, err)
+		}
+	}
+	if _, err := mjaiTile("5z"); err == nil {
+		t.Error("mjaiTile(5z) should fail")
+	}
+}
@@ -0,0 +1,281 @@
+package mahjong
+
+import (
+	"sort"
+	"strings"
+)
+
+//牌の表記、数字の後に色 m p s z、0は赤五、花牌は f で梅蘭菊竹春夏秋冬の順
+//副露は c(チー) p(ポン) k(明槓) a(暗槓) の後に続ける
+//例: 123m406p11z c789s k5555z
+var suitLetters = map[byte]TileType{'p': Dots1, 's': Bamboo1, 'm': Characters1, 'z': East, 'f': PlumBlossom}
+
+//副露
+type Meld struct {
+	FuuroType
+	Tiles []Tile
+}
+
+//手牌と副露、AkaDoraは0と書く赤五の枚数
+type Hand struct {
+	Tiles   []Tile
+	Melds   []Meld
+	AkaDora int `json:",omitempty"`
+}
+
+var meldMarkers = map[byte]FuuroType{'c': ShunTsu, 'p': MinKo, 'k': MinKan, 'a': AnKan}
+
+var meldSizes = map[FuuroType]int{ShunTsu: 3, MinKo: 3, MinKan: 4, AnKan: 4}
+
+func ParseHand(notation string) (*Hand, error) {
+	hand := &Hand{}
+	used := make(map[Tile]bool)
+	reds := make(map[TileType]bool)
+	marker := byte(0)
+	digits := make([]byte, 0)
+	for i := 0; i < len(notation); i++ {
+		c := notation[i]
+		switch {
+		case c == ' ':
+			if len(digits) != 0 {
+				return nil, InvalidNotation{notation}
+			}
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case len(digits) == 0:
+			//副露の記号
+			if _, ok := meldMarkers[c]; !ok || marker != 0 {
+				return nil, InvalidNotation{notation}
+			}
+			marker = c
+		default:
+			tiles, err := parseDigits(digits, c, used, reds)
+			if err != nil {
+				return nil, InvalidNotation{notation}
+			}
+			digits = digits[:0]
+			if marker == 0 {
+				hand.Tiles = append(hand.Tiles, tiles...)
+				continue
+			}
+			meld, err := newMeld(meldMarkers[marker], tiles)
+			if err != nil {
+				return nil, InvalidNotation{notation}
+			}
+			hand.Melds = append(hand.Melds, meld)
+			marker = 0
+		}
+	}
+	if len(digits) != 0 || marker != 0 {
+		return nil, InvalidNotation{notation}
+	}
+	//赤ドラは筒子・索子・萬子の順なので、0と書いた一番後ろの色までが赤
+	for i, five := range []TileType{Dots5, Bamboo5, Characters5} {
+		if reds[five] {
+			hand.AkaDora = i + 1
+		}
+	}
+	//赤になる色で0と書かずに五を4枚使うことはできない
+	for _, five := range []TileType{Dots5, Bamboo5, Characters5} {
+		red := Tile{TileType: five}
+		if used[red] && !reds[five] && red.IsAka(hand.AkaDora) {
+			return nil, InvalidNotation{notation}
+		}
+	}
+	return hand, nil
+}
+
+//副露のない手牌
+func ParseTiles(notation string) ([]Tile, error) {
+	hand, err := ParseHand(notation)
+	if err != nil {
+		return nil, err
+	}
+	if len(hand.Melds) != 0 {
+		return nil, InvalidNotation{notation}
+	}
+	return hand.Tiles, nil
+}
+
+func ParseTile(notation string) (Tile, error) {
+	tiles, err := ParseTiles(notation)
+	if err != nil {
+		return Tile{}, err
+	}
+	if len(tiles) != 1 {
+		return Tile{}, InvalidNotation{notation}
+	}
+	return tiles[0], nil
+}
+
+//赤五はId 0、それ以外の五は1から3で4枚目は0と書いていなければ0、他は0から3を空いている順に振る
+//同じ種類が4枚を超えたら不正
+func parseDigits(digits []byte, suit byte, used map[Tile]bool, reds map[TileType]bool) ([]Tile, error) {
+	first, ok := suitLetters[suit]
+	if !ok {
+		return nil, InvalidNotation{string(suit)}
+	}
+	tiles := make([]Tile, 0, len(digits))
+	for _, digit := range digits {
+		number := TileType(digit - '0')
+		red := number == 0
+		if red {
+			number = 5
+		}
+		if first == East && (red || number > 7) {
+			return nil, InvalidNotation{string(digit)}
+		}
+		if first == PlumBlossom && (red || number > 8) {
+			return nil, InvalidNotation{string(digit)}
+		}
+		tile := Tile{TileType: first + number - 1}
+		five := tile.TileType.Number() == 5 && tile.TileType.IsSuit()
+		if !red && five {
+			tile.Id = 1
+		}
+		for used[tile] && !red && tile.Id < 3 {
+			tile.Id++
+		}
+		if used[tile] && !red && five && !reds[tile.TileType] {
+			tile.Id = 0
+		}
+		if used[tile] {
+			return nil, InvalidNotation{string(digit) + string(suit)}
+		}
+		if red {
+			reds[tile.TileType] = true
+		}
+		used[tile] = true
+		tiles = append(tiles, tile)
+	}
+	return tiles, nil
+}
+
+func newMeld(fuuro FuuroType, tiles []Tile) (Meld, error) {
+	meld := Meld{FuuroType: fuuro, Tiles: SortTiles(tiles)}
+	if len(tiles) != meldSizes[fuuro] {
+		return meld, InvalidNotation{FormatTiles(tiles, 3)}
+	}
+	types := toTileTypes(meld.Tiles)
+	valid := false
+	switch fuuro {
+	case ShunTsu:
+		valid = IsXYZ(types[0], types[1], types[2])
+	case MinKo:
+		valid = IsXXX(types[0], types[1], types[2])
+	default:
+		valid = IsXXXX(types[0], types[1], types[2], types[3])
+	}
+	if !valid {
+		return meld, InvalidNotation{FormatTiles(tiles, 3)}
+	}
+	return meld, nil
+}
+
+//m p s z f の順に並べる、akaDora枚の赤ドラだけ0と書く
+func FormatTiles(tiles []Tile, akaDora int) string {
+	groups := make(map[byte][]Tile)
+	for _, tile := range tiles {
+		groups[suitLetter(tile.TileType)] = append(groups[suitLetter(tile.TileType)], tile)
+	}
+	var builder strings.Builder
+	for _, suit := range []byte("mpszf") {
+		group := groups[suit]
+		if len(group) == 0 {
+			continue
+		}
+		sort.SliceStable(
+			group, func(i, j int) bool {
+				return group[i].TileType < group[j].TileType
+			},
+		)
+		for _, tile := range group {
+			if tile.IsAka(akaDora) {
+				builder.WriteByte('0')
+			} else if tile.IsFlower() {
+				builder.WriteByte(byte('1' + tile.TileType - PlumBlossom))
+			} else {
+				builder.WriteByte(byte('0' + tile.Number()))
+			}
+		}
+		builder.WriteByte(suit)
+	}
+	return builder.String()
+}
+
+func suitLetter(tileType TileType) byte {
+	switch {
+	case tileType.IsDots():
+		return 'p'
+	case tileType.IsBamboo():
+		return 's'
+	case tileType.IsCharacter():
+		return 'm'
+	case tileType.IsHonor():
+		return 'z'
+	case tileType.IsFlower():
+		return 'f'
+	}
+	return '?'
+}
+
+func (hand *Hand) String() string {
+	parts := make([]string, 0, len(hand.Melds)+1)
+	if len(hand.Tiles) != 0 {
+		parts = append(parts, FormatTiles(hand.Tiles, hand.AkaDora))
+	}
+	for _, meld := range hand.Melds {
+		for marker, fuuro := range meldMarkers {
+			if fuuro == meld.FuuroType {
+				parts = append(parts, string(marker)+FormatTiles(meld.Tiles, hand.AkaDora))
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+//手牌と副露をそのまま置き換える
+func (player *Player) SetHand(hand *Hand) {
+	player.Tiles = append([]Tile(nil), hand.Tiles...)
+	player.XYZs, player.XXXs, player.XXXXs = nil, nil, nil
+	for _, meld := range hand.Melds {
+		switch meld.FuuroType {
+		case ShunTsu:
+			player.XYZs = append(player.XYZs, Sequential{TilesXYZ{meld.Tiles[0], meld.Tiles[1], meld.Tiles[2]}, false})
+		case MinKo:
+			player.XXXs = append(player.XXXs, Triplet{TilesXXX{meld.Tiles[0], meld.Tiles[1], meld.Tiles[2]}, false})
+		default:
+			player.XXXXs = append(
+				player.XXXXs, Quad{TilesXXXX: meld.Tiles[0].TileType, Concealed: meld.FuuroType == AnKan},
+			)
+		}
+	}
+}
+
+//ルールの赤ドラの枚数で0と書く
+func (player *Player) Hand(akaDora int) *Hand {
+	return &Hand{Tiles: SortTiles(append([]Tile(nil), player.Tiles...)), Melds: player.melds(), AkaDora: akaDora}
+}
+
+func (player *Player) melds() []Meld {
+	var melds []Meld
+	for _, xyz := range player.XYZs {
+		melds = append(melds, Meld{ShunTsu, xyz.TilesXYZ[:]})
+	}
+	for _, xxx := range player.XXXs {
+		melds = append(melds, Meld{MinKo, xxx.TilesXXX[:]})
+	}
+	for _, xxxx := range player.XXXXs {
+		fuuro := MinKan
+		if xxxx.Concealed {
+			fuuro = AnKan
+		}
+		//槓子は同じ種類の4枚すべて、赤かどうかはAkaDoraで決まる
+		tiles := make([]Tile, 4)
+		for i := range tiles {
+			tiles[i] = Tile{TileType: xxxx.TilesXXXX, Id: int8(i)}
+		}
+		melds = append(melds, Meld{fuuro, tiles})
+	}
+	return melds
+}
@@ -0,0 +1,135 @@
+package mahjong
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHand(t *testing.T) {
+	tests := []struct {
+		notation string
+		tiles    []TileType
+		melds    []FuuroType
+		want     string
+	}{
+		{"123m456p789s11z", []TileType{
+			Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, East, East,
+		}, nil, "123m456p789s11z"},
+		{"11z789s", []TileType{East, East, Bamboo7, Bamboo8, Bamboo9}, nil, "789s11z"},
+		{"406m77z c789s", []TileType{Characters4, Characters5, Characters6, Red, Red}, []FuuroType{ShunTsu}, "406m77z c789s"},
+		{"p555z k1111m a2222p", nil, []FuuroType{MinKo, MinKan, AnKan}, "p555z k1111m a2222p"},
+		{"18f11z", []TileType{PlumBlossom, Winter, East, East}, nil, "11z18f"},
+		//0と書かなければ4枚目の五も赤ではない
+		{"5555m11z", nil, nil, "5555m11z"},
+		{"11z k5555m", nil, []FuuroType{MinKan}, "11z k5555m"},
+		{"5555m05p", nil, nil, "5555m05p"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.notation, func(t *testing.T) {
+				hand, err := ParseHand(tt.notation)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := toTileTypes(hand.Tiles); len(tt.tiles) != 0 && !reflect.DeepEqual(got, tt.tiles) {
+					t.Errorf("Tiles = %v, want %v", got, tt.tiles)
+				}
+				var melds []FuuroType
+				for _, meld := range hand.Melds {
+					melds = append(melds, meld.FuuroType)
+				}
+				if !reflect.DeepEqual(melds, tt.melds) {
+					t.Errorf("Melds = %v, want %v", melds, tt.melds)
+				}
+				if got := hand.String(); got != tt.want {
+					t.Errorf("String() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestParseHand_Invalid(t *testing.T) {
+	for _, notation := range []string{"123", "12x", "8z", "0z", "9f", "c12m", "p123m", "123m c", "c p123m", "k111m"} {
+		if _, err := ParseHand(notation); err == nil {
+			t.Errorf("ParseHand(%q) should fail", notation)
+		}
+	}
+}
+
+func TestParseTiles_Red(t *testing.T) {
+	tiles, err := ParseTiles("5055p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//赤五だけがId 0
+	for i, tile := range tiles {
+		if tile.IsRed() != (i == 1) {
+			t.Errorf("tiles[%v] = %+v", i, tile)
+		}
+	}
+	//赤以外の五は1から3
+	if tiles[0].Id != 1 || tiles[2].Id != 2 || tiles[3].Id != 3 {
+		t.Errorf("tiles = %+v, want ids 1, 0, 2, 3", tiles)
+	}
+}
+
+func TestFormatTiles_AkaDora(t *testing.T) {
+	tiles, err := ParseTiles("055m05p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		akaDora int
+		want    string
+	}{
+		{0, "555m55p"},
+		{1, "555m05p"},
+		{3, "055m05p"},
+	}
+	for _, tt := range tests {
+		if got := FormatTiles(tiles, tt.akaDora); got != tt.want {
+			t.Errorf("FormatTiles(%v) = %v, want %v", tt.akaDora, got, tt.want)
+		}
+	}
+}
+
+func TestPlayer_SetHand(t *testing.T) {
+	hand, err := ParseHand("23406m c789s p111z a0555p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := &Player{}
+	player.SetHand(hand)
+	if len(player.Tiles) != 5 || len(player.XYZs) != 1 || len(player.XXXs) != 1 || len(player.XXXXs) != 1 {
+		t.Fatalf("player = %+v", player)
+	}
+	if !player.XXXXs[0].Concealed || player.Concealed() {
+		t.Errorf("XXXXs = %+v", player.XXXXs)
+	}
+	if got := player.Hand(hand.AkaDora).String(); got != "23406m c789s p111z a0555p" {
+		t.Errorf("Hand() = %v", got)
+	}
+	//槓子は赤五を含む4枚そのもの
+	if got := player.Hand(hand.AkaDora).Melds[2].Tiles; !reflect.DeepEqual(got, hand.Melds[2].Tiles) {
+		t.Errorf("Hand().Melds[2] = %+v, want %+v", got, hand.Melds[2].Tiles)
+	}
+	if got := player.Hand(0).String(); got != "23456m c789s p111z a5555p" {
+		t.Errorf("Hand(0) = %v", got)
+	}
+}
+
+//赤ドラのない槓子は赤五を作らない
+func TestPlayer_Hand_PlainQuad(t *testing.T) {
+	for _, notation := range []string{"11z k5555m", "11z a5555p", "11z k0555m"} {
+		hand, err := ParseHand(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		player := &Player{}
+		player.SetHand(hand)
+		if got := player.Hand(hand.AkaDora).String(); got != notation {
+			t.Errorf("Hand() of %q = %v", notation, got)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package mahjong
+
+import "errors"
+
+//ルール設定
+type RuleOptions struct {
+	//赤ドラの枚数、筒子・索子・萬子の順に一枚ずつ
+	AkaDora int
+	//喰い断
+	Kuitan bool
+	//オープン立直
+	OpenRiichi bool
+	//切り上げ満貫
+	KiriageMangan bool
+	//役満の複合
+	DoubleYakuman bool
+	//ダブロン、なしなら頭ハネ
+	MultipleRon bool
+	//三人麻雀のツモは北家の分を折半する、なしならツモ損
+	NorthBisection bool
+
+	//持ち点と返し
+	StartingPoints int
+	ReturnPoints   int
+	//順位ウマ、オカは(返し-持ち点)*人数をトップへ
+	Uma [4]int
+	//トビ
+	Tobi bool
+
+	//途中流局
+	KyuushuKyuuhai bool
+	SuufonRenda    bool
+	Suukaikan      bool
+	SuuchaRiichi   bool
+	Sanchahou      bool
+}
+
+var DefaultRuleOptions = RuleOptions{
+	Kuitan:         true,
+	KiriageMangan:  true,
+	StartingPoints: 25000,
+	ReturnPoints:   30000,
+	Tobi:           true,
+	KyuushuKyuuhai: true,
+	SuufonRenda:    true,
+	Suukaikan:      true,
+	SuuchaRiichi:   true,
+	Sanchahou:      true,
+}
+
+//Mリーグ
+var MLeagueRuleOptions = RuleOptions{
+	AkaDora:        3,
+	Kuitan:         true,
+	KiriageMangan:  true,
+	MultipleRon:    true,
+	StartingPoints: 25000,
+	ReturnPoints:   30000,
+	Uma:            [4]int{30, 10, -10, -30},
+	KyuushuKyuuhai: true,
+	SuufonRenda:    true,
+	Suukaikan:      true,
+	SuuchaRiichi:   true,
+	Sanchahou:      true,
+}
+
+//天鳳
+var TenhouRuleOptions = RuleOptions{
+	AkaDora:        3,
+	Kuitan:         true,
+	DoubleYakuman:  true,
+	MultipleRon:    true,
+	StartingPoints: 25000,
+	ReturnPoints:   30000,
+	Uma:            [4]int{20, 10, -10, -20},
+	Tobi:           true,
+	KyuushuKyuuhai: true,
+	SuufonRenda:    true,
+	Suukaikan:      true,
+	SuuchaRiichi:   true,
+	Sanchahou:      true,
+}
+
+//三人麻雀、ツモ損
+var SanmaRuleOptions = RuleOptions{
+	AkaDora:        2,
+	Kuitan:         true,
+	KiriageMangan:  true,
+	StartingPoints: 35000,
+	ReturnPoints:   40000,
+	Uma:            [4]int{15, 0, -15, 0},
+	Tobi:           true,
+	KyuushuKyuuhai: true,
+	Suukaikan:      true,
+}
+
+//World Riichi Championship
+var WRCRuleOptions = RuleOptions{
+	Kuitan:         true,
+	StartingPoints: 30000,
+	ReturnPoints:   30000,
+	Uma:            [4]int{15, 5, -5, -15},
+}
+
+//未設定ならDefaultRuleOptions
+func (rule JapaneseBaseRule) options() *RuleOptions {
+	if rule.Options == nil {
+		return &DefaultRuleOptions
+	}
+	return rule.Options
+}
+
+//ルール設定を持つルール
+type optionsRule interface {
+	options() *RuleOptions
+}
+
+//赤ドラの枚数、赤ドラのないルールは0
+func (maj *Mahjong) AkaDora() int {
+	if rule, ok := maj.Rule.(optionsRule); ok {
+		return rule.options().AkaDora
+	}
+	return 0
+}
+
+//赤ドラはIdが0から
+func (tile Tile) IsAka(count int) bool {
+	var suit int
+	switch tile.TileType {
+	case Dots5:
+		suit = 0
+	case Bamboo5:
+		suit = 1
+	case Characters5:
+		suit = 2
+	default:
+		return false
+	}
+	reds := count / 3
+	if suit < count%3 {
+		reds++
+	}
+	return int(tile.Id) < reds
+}
+
+func (rule JapaneseBaseRule) StartingPoints() int {
+	return rule.options().StartingPoints
+}
+
+//返し
+func (rule JapaneseBaseRule) ReturnPoints() int {
+	return rule.options().ReturnPoints
+}
+
+//ウマ・オカ込みの最終ポイント
+func (rule JapaneseBaseRule) Settle(placements []Placement) {
+	options := rule.options()
+	oka := (options.ReturnPoints - options.StartingPoints) * len(placements)
+	for i := range placements {
+		placement := &placements[i]
+		score := placement.Score - options.ReturnPoints
+		if placement.Rank == 1 {
+			score += oka
+		}
+		placement.Point = float64(score)/1000 + float64(options.Uma[placement.Rank-1])
+	}
+}
+
+//ダブロン・トリロンの可否
+func (rule JapaneseBaseRule) CanMultiRon(players []*Player) error {
+	options := rule.options()
+	switch {
+	case len(players) == 0 || len(players) > 3:
+		return errors.New("invalid number of players")
+	case len(players) == 3 && options.Sanchahou:
+		return errors.New("triple ron is a draw")
+	case len(players) > 1 && !options.MultipleRon:
+		return errors.New("multiple ron is not allowed")
+	}
+	for _, player := range players {
+		if _, err := rule.CanRon(player); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package mahjong
+
+import "testing"
+
+func TestTile_IsAka(t *testing.T) {
+	tests := []struct {
+		name  string
+		tile  Tile
+		count int
+		want  bool
+	}{
+		{"赤なし", Tile{TileType: Dots5}, 0, false},
+		{"赤五筒", Tile{TileType: Dots5}, 1, true},
+		{"一枚なら索子は黒", Tile{TileType: Bamboo5}, 1, false},
+		{"赤五萬", Tile{TileType: Characters5}, 3, true},
+		{"二枚目の赤五筒", Tile{TileType: Dots5, Id: 1}, 4, true},
+		{"二枚目の五索", Tile{TileType: Bamboo5, Id: 1}, 4, false},
+		{"五以外", Tile{TileType: Dots4}, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := tt.tile.IsAka(tt.count); got != tt.want {
+					t.Errorf("IsAka() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestJapaneseBaseRule_score(t *testing.T) {
+	fourFan := Agari{YakuTachi: []Yaku{{FanFR: 一飜, FanMZ: 一飜}, {FanFR: 三飜, FanMZ: 三飜}}, Fu: 30}
+	doubleYakuman := Agari{YakuTachi: []Yaku{{FanFR: 役満, FanMZ: 役満}, {FanFR: 役満, FanMZ: 役満}}}
+	kazoe := Agari{YakuTachi: []Yaku{{FanFR: 六飜, FanMZ: 六飜}}, Dora: 20}
+	tests := []struct {
+		name    string
+		agari   Agari
+		options RuleOptions
+		want    ScoreSrc
+	}{
+		{"切り上げ満貫", fourFan, DefaultRuleOptions, 満貫},
+		{"切り上げなし", fourFan, TenhouRuleOptions, 1920},
+		{"役満の複合", doubleYakuman, TenhouRuleOptions, 数え役満 * 2},
+		{"役満の複合なし", doubleYakuman, DefaultRuleOptions, 数え役満},
+		{"数え役満は複合しない", kazoe, TenhouRuleOptions, 数え役満},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				options := tt.options
+				rule := JapaneseBaseRule{Options: &options}
+				if got := rule.score(tt.agari, true); got != tt.want {
+					t.Errorf("score() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestJapaneseBaseRule_Agaris_Kuitan(t *testing.T) {
+	maj, rule := newTestMahjong()
+	p0 := maj.Players.Now()
+	p0.Tiles = toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Dots2, Dots3, Dots4, Bamboo6, Bamboo7, Bamboo8, Dots6, Dots6,
+		},
+	)
+	p0.XXXs = []Triplet{{TilesXXX: TilesXXX{{TileType: Bamboo3}, {TileType: Bamboo3}, {TileType: Bamboo3}}}}
+	p0.LastDraw = Tile{TileType: Dots6}
+	if len(rule.Agaris(p0, Tile{})) == 0 {
+		t.Error("Agaris() with kuitan = nil")
+	}
+	rule.Options = &RuleOptions{}
+	if agaris := rule.Agaris(p0, Tile{}); len(agaris) != 0 {
+		t.Errorf("Agaris() without kuitan = %v", agaris)
+	}
+}
+
+func TestJapaneseBaseRule_Settle(t *testing.T) {
+	rule := JapaneseBaseRule{Options: &TenhouRuleOptions}
+	placements := []Placement{{Rank: 1, Score: 45000}, {Rank: 2, Score: 25000}, {Rank: 3, Score: 20000}, {Rank: 4, Score: 10000}}
+	rule.Settle(placements)
+	want := []float64{55, 5, -20, -40}
+	for i, placement := range placements {
+		if placement.Point != want[i] {
+			t.Errorf("Settle()[%v] = %v, want %v", i, placement.Point, want[i])
+		}
+	}
+}
+
+func TestJapaneseBaseRule_IsGameOver_Tobi(t *testing.T) {
+	maj := newTestGame(t)
+	setScores(maj, 60000, 45000, -5000, 0)
+	rule := JapaneseBaseRule{BaseRule: BaseRule{maj}}
+	if !rule.IsGameOver(false) {
+		t.Error("IsGameOver() with tobi = false")
+	}
+	rule.Options = &WRCRuleOptions
+	if rule.IsGameOver(false) {
+		t.Error("IsGameOver() without tobi = true")
+	}
+}
+
+func TestMahjong_MultiRon(t *testing.T) {
+	maj, _ := newTestMahjong()
+	discarder := maj.Players.FindField(EastField)
+	discarder.Phase.Change(Idle)
+	maj.LastTile = Tile{TileType: Dots5}
+	maj.LastTilePlayer = discarder
+	maj.Round.Honba = 1
+	maj.Deposit = RiichiDeposit
+	waiting := toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+			Dots2, Dots2, Dots4, Dots6,
+		},
+	)
+	west := maj.Players.FindField(WestField)
+	south := maj.Players.FindField(SouthField)
+	west.Tiles = waiting
+	south.Tiles = waiting
+	if _, err := maj.MultiRon(west, south); err == nil {
+		t.Error("MultiRon() should fail with atamahane")
+	}
+
+	maj.Rule = &JapaneseHanChanRule{JapaneseBaseRule{BaseRule{maj}, &TenhouRuleOptions}}
+	results, err := maj.MultiRon(west, south)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Winner != south || results[1].Winner != west {
+		t.Fatalf("MultiRon() = %v", results)
+	}
+	if results[0].Honba != 300 || results[0].Deposit != RiichiDeposit || results[1].Honba != 0 || results[1].Deposit != 0 {
+		t.Errorf("MultiRon() bonus = %+v, %+v", results[0], results[1])
+	}
+	if len(maj.Result.Wins) != 2 || !maj.Result.Done() {
+		t.Errorf("Result = %+v", maj.Result)
+	}
+}
@@ -8,15 +8,22 @@ type Player struct {
 	FieldWind
 	Score int
 	Phase
-	jun      Jun
-	Riichi   Jun
-	Tiles    []Tile
-	XXXs     []Triplet
-	XYZs     []Sequential
-	XXXXs    []Quad
+	jun        Jun
+	Riichi     Jun
+	OpenRiichi bool
+	Tiles      []Tile
+	XXXs       []Triplet
+	XYZs       []Sequential
+	XXXXs      []Quad
+	//抜いた北
+	Kita []Tile
+	//脇に置いた花牌
+	Flowers  []Tile
 	Discards []DiscardTile
 	LastDraw Tile
 	Through  bool
+	rinshan  bool
+	ippatsu  bool
 }
 
 func (player *Player) HasDiscarded(tile Tile) bool {
@@ -54,16 +61,54 @@ func (player *Player) GetTileTypeIndexes(tileType TileType) []int {
 	return indexes
 }
 
+//親は局ごとに下家へ移る
 func (player *Player) Wind(round Round) FieldWind {
-	return (player.FieldWind + FieldWind(round.Number)) % 4
+	seats := round.seats()
+	return (player.FieldWind - FieldWind(round.Number)%seats + seats) % seats
 }
 
 func (player *Player) IsParent(round Round) bool {
-	return player.Wind(round) == round.FieldWind
+	return player.Wind(round) == EastField
 }
 
+//手牌と副露牌
+func (player *Player) AllTiles() []Tile {
+	tiles := make([]Tile, len(player.Tiles))
+	copy(tiles, player.Tiles)
+	for _, xxx := range player.XXXs {
+		tiles = append(tiles, xxx.TilesXXX[:]...)
+	}
+	for _, xyz := range player.XYZs {
+		tiles = append(tiles, xyz.TilesXYZ[:]...)
+	}
+	for _, xxxx := range player.XXXXs {
+		for id := int8(0); id < 4; id++ {
+			tiles = append(tiles, Tile{TileType: xxxx.TilesXXXX, Id: id})
+		}
+	}
+	return tiles
+}
+
+//和了牌を加えた手牌と副露の枚数、槓子も三枚と数える
+func (player *Player) handSize(last Tile) int {
+	size := len(player.Tiles) + 3*(len(player.XYZs)+len(player.XXXs)+len(player.XXXXs))
+	if last.TileType != None {
+		size++
+	}
+	return size
+}
+
+//暗槓は門前のまま
 func (player *Player) Concealed() bool {
-	return len(player.Tiles) >= 13
+	if len(player.XXXs) != 0 || len(player.XYZs) != 0 {
+		return false
+	}
+	for _, xxxx := range player.XXXXs {
+		if !xxxx.Concealed {
+			return false
+		}
+	}
+	return true
 }
 
 type Phase int8
@@ -102,13 +147,18 @@ type Players struct {
 
 func NewPlayers(n int) Players {
 	r := ring.New(n)
-	for i := 0; i < 4; i++ {
+	for i := 0; i < n; i++ {
 		r.Value = new(Player)
 		r = r.Next()
 	}
 	return Players{r}
 }
 
+//席の数
+func (players *Players) Len() int {
+	return players.ring.Len()
+}
+
 func (players *Players) Now() *Player {
 	return players.ring.Value.(*Player)
 }
@@ -44,3 +44,38 @@ func TestPlayers_find(t *testing.T) {
 		t.Error()
 	}
 }
+
+func TestNewPlayers(t *testing.T) {
+	for _, n := range []int{3, 4} {
+		players := NewPlayers(n)
+		if players.Len() != n {
+			t.Errorf("NewPlayers(%v).Len() = %v", n, players.Len())
+		}
+		players.Do(
+			func(player *Player) {
+				if player == nil {
+					t.Errorf("NewPlayers(%v) has an empty seat", n)
+				}
+			},
+		)
+	}
+}
+
+func TestPlayer_Wind(t *testing.T) {
+	tests := []struct {
+		seat, number, seats int8
+		want                FieldWind
+	}{
+		{1, 1, 4, EastField},
+		{0, 1, 4, NorthField},
+		{0, 1, 3, WestField},
+		{2, 2, 3, EastField},
+		{0, 1, 0, NorthField},
+	}
+	for _, tt := range tests {
+		player := Player{FieldWind: FieldWind(tt.seat)}
+		if got := player.Wind(Round{Number: tt.number, Seats: tt.seats}); got != tt.want {
+			t.Errorf("Wind() of seat %v in %v/%v = %v, want %v", tt.seat, tt.number, tt.seats, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package mahjong
+
+import (
+	"errors"
+	"strconv"
+)
+
+//牌譜の打牌や鳴き
+type LogActionType int8
+
+const (
+	LogDraw LogActionType = iota
+	LogDrawKan
+	LogDiscard
+	LogRiichi
+	LogChii
+	LogPon
+	LogKan
+	LogAnKan
+	LogKaKan
+	LogTsumo
+	LogRon
+	LogNineYaochus
+	LogTripleRon
+	LogRyuukyoku
+)
+
+//牌譜の牌は赤五をId 0、それ以外をId 1以上で表す
+type LogAction struct {
+	Type LogActionType
+	Seat FieldWind
+	Tile
+	//チー・ポンで晒す手牌
+	Consumed []Tile
+}
+
+//牌譜の一局、席は起家から順に0から3
+type HandLog struct {
+	Round
+	Deposit           int
+	Scores            [4]int
+	Haipai            [4][]Tile
+	DoraIndicators    []Tile
+	UraDoraIndicators []Tile
+	Actions           []LogAction
+	//記録された点数移動、積み棒と供託を含む
+	Deltas [4]int
+}
+
+type GameLog struct {
+	Hands []HandLog
+}
+
+//牌譜とエンジンの点数移動の食い違い
+type Divergence struct {
+	Hand     int
+	Round    Round
+	Recorded [4]int
+	Engine   [4]int
+	Err      error
+}
+
+func (divergence Divergence) String() string {
+	str := "hand " + strconv.Itoa(divergence.Hand) + ": "
+	if divergence.Err != nil {
+		return str + divergence.Err.Error()
+	}
+	for seat := range divergence.Recorded {
+		if seat > 0 {
+			str += ", "
+		}
+		str += strconv.Itoa(divergence.Recorded[seat]) + "/" + strconv.Itoa(divergence.Engine[seat])
+	}
+	return str
+}
+
+type ReplayReport struct {
+	Hands       int
+	Divergences []Divergence
+}
+
+//一局ごとに配牌から並べ直した山で再生し、点数移動を比べる
+func ReplayLog(rule Rule, log *GameLog) *ReplayReport {
+	maj := InitWithSeed(rule, 0)
+	report := &ReplayReport{Hands: len(log.Hands)}
+	for i := range log.Hands {
+		hand := &log.Hands[i]
+		deltas, err := maj.replayHand(hand)
+		if err != nil || deltas != hand.Deltas {
+			report.Divergences = append(
+				report.Divergences, Divergence{Hand: i, Round: hand.Round, Recorded: hand.Deltas, Engine: deltas, Err: err},
+			)
+		}
+	}
+	return report
+}
+
+func (maj *Mahjong) replayHand(hand *HandLog) ([4]int, error) {
+	var deltas [4]int
+	maj.Result.Init()
+	maj.GameOver = nil
+	maj.Round.FieldWind = hand.FieldWind
+	maj.Round.Number = hand.Number
+	maj.Round.Honba = hand.Honba
+	maj.resetHand()
+	maj.Deposit = hand.Deposit
+	maj.Players.Do(
+		func(player *Player) {
+			player.Score = hand.Scores[player.FieldWind]
+		},
+	)
+	wall, err := buildWall(maj.Rule.Tiles(), hand)
+	if err != nil {
+		return deltas, err
+	}
+	maj.Tiles = wall
+	maj.Haipai()
+
+	actions := hand.Actions
+	//親の第一ツモは配牌で引いている
+	if len(actions) > 0 && actions[0].Type == LogDraw {
+		actions = actions[1:]
+	}
+	for i := 0; i < len(actions); i++ {
+		action := actions[i]
+		if action.Type == LogRon {
+			//続くロンはまとめて和了する
+			players := []*Player{maj.Players.FindField(action.Seat)}
+			for i+1 < len(actions) && actions[i+1].Type == LogRon {
+				i++
+				players = append(players, maj.Players.FindField(actions[i].Seat))
+			}
+			if err := maj.replayRon(players); err != nil {
+				return deltas, err
+			}
+			continue
+		}
+		if err := maj.replayAction(action); err != nil {
+			return deltas, errors.New("action " + strconv.Itoa(i) + ": " + err.Error())
+		}
+	}
+	if !maj.Result.Done() {
+		return deltas, errors.New("Hand did not end. ")
+	}
+	for _, win := range maj.Result.Wins {
+		for seat := range deltas {
+			deltas[seat] += win.Deltas[seat]
+		}
+	}
+	if maj.Result.Ryuukyoku != nil {
+		deltas = maj.Result.Ryuukyoku.Deltas
+	}
+	return deltas, nil
+}
+
+func (maj *Mahjong) replayRon(players []*Player) error {
+	if len(players) == 1 {
+		_, err := maj.ron(players[0])
+		return err
+	}
+	_, err := maj.multiRon(players...)
+	return err
+}
+
+func (maj *Mahjong) replayAction(action LogAction) error {
+	player := maj.Players.FindField(action.Seat)
+	var err error
+	switch action.Type {
+	case LogDraw:
+		if err = maj.replayPass(); err != nil {
+			return err
+		}
+		var tile Tile
+		if tile, err = maj.Draw(player); err == nil && tile.TileType != action.TileType {
+			err = errors.New("Drew " + TilesName[tile.TileType] + " instead of " + TilesName[action.TileType])
+		}
+	case LogDrawKan:
+		if err = maj.replayPass(); err != nil {
+			return err
+		}
+		_, err = maj.DrawKan(player)
+	case LogDiscard:
+		err = maj.Dahai(player, findLogTile(player.Tiles, action.Tile))
+	case LogRiichi:
+		err = maj.Riichi(player, findLogTile(player.Tiles, action.Tile))
+	case LogChii, LogPon:
+		consumed := make([]Tile, 0, 2)
+		rest := player.Tiles
+		for _, tile := range action.Consumed {
+			found := findLogTile(rest, tile)
+			consumed = append(consumed, found)
+			rest = removeTile(rest, found)
+		}
+		if len(consumed) != 2 {
+			return errors.New("Call needs 2 tiles. ")
+		}
+		claimType := ClaimPon
+		if action.Type == LogChii {
+			claimType = ClaimChii
+		}
+		err = maj.replayClaim(player, Claim{claimType, consumed})
+	case LogKan:
+		err = maj.replayClaim(player, Claim{ClaimType: ClaimKan})
+	case LogAnKan:
+		err = maj.AnKan(player, action.TileType)
+	case LogKaKan:
+		err = maj.KaKan(player, findLogTile(player.Tiles, action.Tile))
+	case LogTsumo:
+		_, err = maj.Tsumo(player)
+	case LogNineYaochus:
+		_, err = maj.NineYaochus(player)
+	case LogTripleRon:
+		players := make([]*Player, 0)
+		maj.Players.Do(
+			func(p *Player) {
+				if _, err := maj.Rule.CanRon(p); err == nil {
+					players = append(players, p)
+				}
+			},
+		)
+		_, err = maj.tripleRon(players...)
+	case LogRyuukyoku:
+		_, err = maj.Ryuukyoku()
+	}
+	return err
+}
+
+//牌譜に見送りは残らないので、ツモの前に時間切れとして受付を閉じる
+func (maj *Mahjong) replayPass() error {
+	if maj.ClaimWindow == nil {
+		return nil
+	}
+	_, err := maj.ResolveClaims()
+	return err
+}
+
+//鳴いた人だけが宣言し、他の人は時間切れのパスにする
+func (maj *Mahjong) replayClaim(player *Player, claim Claim) error {
+	if maj.ClaimWindow == nil {
+		return errors.New("No claim window. ")
+	}
+	result, err := maj.Declare(player, claim)
+	if err != nil || result != nil {
+		return err
+	}
+	_, err = maj.ResolveClaims()
+	return err
+}
+
+//赤ドラはIdの小さい牌なので、赤なら最小、それ以外は最大のIdを選ぶ
+func findLogTile(tiles []Tile, logTile Tile) Tile {
+	found := Tile{}
+	for _, tile := range tiles {
+		if tile.TileType != logTile.TileType {
+			continue
+		}
+		if found.TileType == None || (logTile.IsRed() && tile.Id < found.Id) || (!logTile.IsRed() && tile.Id > found.Id) {
+			found = tile
+		}
+	}
+	return found
+}
+
+//配牌・ツモ・嶺上牌・ドラ表示牌を牌譜の通りに置き、残りの牌で埋める
+func buildWall(tiles []Tile, hand *HandLog) ([]Tile, error) {
+	pool := make(map[TileType][]Tile)
+	for _, tile := range tiles {
+		pool[tile.TileType] = append(pool[tile.TileType], tile)
+	}
+	wall := make([]Tile, len(tiles))
+	placed := make([]bool, len(tiles))
+	used := make(map[Tile]bool)
+	place := func(index int, logTile Tile) error {
+		if index < 0 || index >= len(wall) || placed[index] {
+			return errors.New("Wall position " + strconv.Itoa(index) + " is invalid. ")
+		}
+		candidates := pool[logTile.TileType]
+		if len(candidates) == 0 {
+			return errors.New("Too many " + TilesName[logTile.TileType] + ". ")
+		}
+		tile := findLogTile(candidates, logTile)
+		pool[logTile.TileType] = removeTile(candidates, tile)
+		used[tile] = true
+		wall[index] = tile
+		placed[index] = true
+		return nil
+	}
+
+	dealer := int(hand.Number) % 4
+	for seat, haipai := range hand.Haipai {
+		if len(haipai) != 13 {
+			return nil, errors.New("Haipai needs 13 tiles. ")
+		}
+		//4枚ずつ3回、親は2枚、子は1枚
+		p := (seat - dealer + 4) % 4
+		for k, tile := range haipai {
+			index := 16*(k/4) + 4*p + k%4
+			if k == 12 {
+				index = 49 + p
+				if p == 0 {
+					index = 48
+				}
+			}
+			if err := place(index, tile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	next, kans := 49, 0
+	for _, action := range hand.Actions {
+		var err error
+		switch action.Type {
+		case LogDraw:
+			err = place(next, action.Tile)
+			next++
+			if next == 50 {
+				next = 53
+			}
+		case LogDrawKan:
+			kans++
+			err = place(len(wall)-kans, action.Tile)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, indicator := range hand.DoraIndicators {
+		if err := place(len(wall)-5-2*i, indicator); err != nil {
+			return nil, err
+		}
+	}
+	for i, indicator := range hand.UraDoraIndicators {
+		if err := place(len(wall)-6-2*i, indicator); err != nil {
+			return nil, err
+		}
+	}
+
+	i := 0
+	for _, tile := range tiles {
+		if used[tile] {
+			continue
+		}
+		for placed[i] {
+			i++
+		}
+		wall[i] = tile
+		i++
+	}
+	return wall, nil
+}
@@ -0,0 +1,123 @@
+package mahjong
+
+//和了結果
+type WinResult struct {
+	Winner      *Player
+	Discarder   *Player
+	Tsumo       bool
+	WinningTile Tile
+	Hand        HandShape
+	YakuTachi   []YakuFan
+	Fan         Fan
+	Fu          int
+	FuItems     []FuItem
+	Limit       string
+	Score       ScoreSrc
+
+	//積み棒と供託の受け取り
+	Honba   int
+	Deposit int
+
+	//席(Player.FieldWind)ごとの点数移動
+	Deltas [4]int
+}
+
+//流局結果
+type RyuukyokuResult struct {
+	Type DrawType
+
+	//席(Player.FieldWind)ごと
+	Tenpai [4]bool
+	Deltas [4]int
+
+	//親の連荘
+	Renchan bool
+}
+
+type DrawType int8
+
+const (
+	//荒牌平局
+	DrawExhaustive DrawType = iota
+	//九種九牌
+	DrawNineYaochus
+	//四風連打
+	DrawFourWinds
+	//四槓散了
+	DrawFourKans
+	//四家立直
+	DrawFourRiichi
+	//三家和
+	DrawTripleRon
+)
+
+var DrawNames = map[DrawType]string{
+	DrawExhaustive:  "荒牌平局",
+	DrawNineYaochus: "九種九牌",
+	DrawFourWinds:   "四風連打",
+	DrawFourKans:    "四槓散了",
+	DrawFourRiichi:  "四家立直",
+	DrawTripleRon:   "三家和",
+}
+
+//不聴罰符
+const NotenPenalty = 3000
+
+type YakuFan struct {
+	Name string
+	Fan  Fan
+}
+
+type FuItem struct {
+	Name string
+	Fu   int
+}
+
+func SumFu(items []FuItem) int {
+	fu := 0
+	for _, item := range items {
+		fu += item.Fu
+	}
+	return fu
+}
+
+//和了形の分解
+type HandShape struct {
+	Tiles      []Tile
+	Head       TilesXX
+	Triplets   []Triplet
+	Sequential []Sequential
+	Quads      []Quad
+	Pairs      []TilesXX
+	Wait       WaitType
+}
+
+type WaitType int8
+
+const (
+	Ryanmen WaitType = iota
+	Kanchan
+	Penchan
+	Shanpon
+	Tanki
+	Nobetan
+	Juusanmen
+)
+
+var WaitNames = map[WaitType]string{
+	Ryanmen:   "両面",
+	Kanchan:   "嵌張",
+	Penchan:   "辺張",
+	Shanpon:   "双碰",
+	Tanki:     "単騎",
+	Nobetan:   "延べ単",
+	Juusanmen: "十三面",
+}
+
+var FuuroNames = map[FuuroType]string{
+	MinKo:   "明刻",
+	AnKo:    "暗刻",
+	MinKan:  "明槓",
+	AnKan:   "暗槓",
+	ShunTsu: "順子",
+}
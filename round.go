@@ -12,25 +12,46 @@ type Round struct {
 
 	//本場
 	Honba int8
+
+	//席の数、親はこの数で一周する
+	Seats int8
 }
 
 func NewRound(maxField FieldWind, maxNumber int8) *Round {
 	return &Round{MaxFieldWind: maxField, MaxNumber: maxNumber}
 }
 
-func (round *Round) ToNext(normal bool) {
-	if normal {
+//連荘なら親はそのまま、流局か連荘なら積み棒を増やす
+func (round *Round) ToNext(renchan bool, honba bool) {
+	if !renchan {
 		round.Number++
-		round.Honba = 0
-		if round.Number > round.MaxNumber {
+		if round.Number >= round.MaxNumber {
+			round.Number = 0
 			round.FieldWind++
 		}
-	} else {
+	}
+	if honba {
 		round.Honba++
+	} else {
+		round.Honba = 0
 	}
 	round.RealNumber++
 }
 
+//未設定なら四人
+func (round Round) seats() FieldWind {
+	if round.Seats == 0 {
+		return 4
+	}
+	return FieldWind(round.Seats)
+}
+
+//オーラス
+func (round *Round) IsAllLast() bool {
+	return round.FieldWind > round.MaxFieldWind ||
+		(round.FieldWind == round.MaxFieldWind && round.Number == round.MaxNumber-1)
+}
+
 type FieldWind int8
 
 const (
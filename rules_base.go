@@ -14,13 +14,23 @@ type Rule interface {
 	WallTilesCannotDraw() uint8
 	CanRiichi(player *Player) ([]Tile, error)
 	Riichi(player *Player, tile Tile) error
+	CanOpenRiichi(player *Player) ([]Tile, error)
+	OpenRiichi(player *Player, tile Tile) error
 	CanRon(player *Player) ([]Agari, error)
-	Ron(player *Player) error
+	Ron(player *Player) (*WinResult, error)
+	CanMultiRon(players []*Player) error
 	CanTsumo(player *Player) ([]Agari, error)
-	Tsumo(player *Player) error
+	Tsumo(player *Player) (*WinResult, error)
 	CanNineYaochus(player *Player) error
-	NineYaochus(player *Player) error
+	NineYaochus(player *Player) (*RyuukyokuResult, error)
+	TripleRon(players []*Player) (*RyuukyokuResult, error)
 	CanAgari(player *Player, last Tile) ([]Agari, error)
+	DoraIndicators() []Tile
+	Renchan() bool
+	IsGameOver(renchan bool) bool
+	Settle(placements []Placement)
+	CanRyuukyoku() (DrawType, error)
+	Ryuukyoku() (*RyuukyokuResult, error)
 }
 
 type BaseRule struct {
@@ -78,6 +88,7 @@ func IsXXXX(ttA, ttB, ttC, ttD TileType) bool {
 type WinningHand interface {
 	readyHand() bool
 	doubleReady() bool
+	openReady() bool
 	oneShot() bool
 	selfPick() bool
 	allSimples() bool
@@ -121,6 +132,8 @@ type WinningHand interface {
 	handOfEarth() bool
 
 	CountFu(bool) int
+	FuItems(bool) []FuItem
+	Shape() HandShape
 }
 
 //和了形
@@ -134,6 +147,8 @@ type WinningHandBase struct {
 	SortedTileTypes       []TileType
 	SortedHandTiles       []Tile
 	RemainderTilesCanDraw uint8
+	Tsumo                 bool
+	Chankan               bool
 }
 
 func (maj *Mahjong) NewWinningHandBase(player, atm *Player, sortedHandTiles []Tile) *WinningHandBase {
@@ -148,6 +163,8 @@ func (maj *Mahjong) NewWinningHandBase(player, atm *Player, sortedHandTiles []Ti
 		SortTileTypes(toTileTypes(sortedHandTiles)),
 		sortedHandTiles,
 		maj.RemainderTilesCanDraw(),
+		false,
+		maj.Chankan != nil,
 	}
 }
 
@@ -160,13 +177,18 @@ func (base *WinningHandBase) newWinningHandNormal(
 
 	hand.XX[0] = head[0].TileType
 	hand.XX[1] = head[1].TileType
-	hand.XXXs = make([][3]TileType, len(triplets))
+	hand.XXXs = make([][3]TileType, len(triplets), len(triplets)+len(quad))
 	hand.XYZs = make([][3]TileType, len(sequential))
 
 	for i, triplet := range triplets {
 		t := triplet.TilesXXX[0].TileType
 		hand.XXXs[i] = [3]TileType{t, t, t}
 	}
+	//槓子も刻子として扱う
+	for _, xxxx := range quad {
+		t := xxxx.TilesXXXX
+		hand.XXXs = append(hand.XXXs, [3]TileType{t, t, t})
+	}
 	for i, sequential := range sequential {
 		for j, tile := range sequential.TilesXYZ {
 			hand.XYZs[i][j] = tile.TileType
@@ -225,6 +247,9 @@ func (base *WinningHandBase) is7PairsWin() *WinningHand7 {
 
 func (base *WinningHandBase) is7PairsWith4SameWin() *WinningHand7 {
 	sortedTileTypes := base.SortedTileTypes
+	if len(sortedTileTypes) != 14 {
+		return nil
+	}
 	hand := WinningHand7{WinningHandBase: *base}
 	for i := 0; i < 14; i += 2 {
 		if !IsXX(sortedTileTypes[i], sortedTileTypes[i+1]) {
@@ -267,19 +292,23 @@ func (base *WinningHandBase) thirteenOrphansWin() *WinningHand13 {
 }
 
 func (base *WinningHandBase) readyHand() bool {
-	return base.Player.Riichi.First()
+	return !base.Player.Riichi.First()
 }
 
 func (base *WinningHandBase) doubleReady() bool {
 	return base.Player.Riichi == 1
 }
 
+func (base *WinningHandBase) openReady() bool {
+	return base.Player.OpenRiichi
+}
+
 func (base *WinningHandBase) oneShot() bool {
-	return base.Player.Riichi == base.Jun
+	return !base.Player.Riichi.First() && base.Player.ippatsu
 }
 
 func (base *WinningHandBase) selfPick() bool {
-	return base.Player.LastDraw != base.LastTile
+	return base.Tsumo
 }
 
 func (base *WinningHandBase) allSimples() bool {
@@ -292,7 +321,7 @@ func (base *WinningHandBase) allSimples() bool {
 }
 
 func (base *WinningHandBase) finalTurnWinSeaMoon() bool {
-	return base.selfPick() && base.RemainderTilesCanDraw == 0
+	return base.selfPick() && base.RemainderTilesCanDraw == 0 && !base.Player.rinshan
 }
 
 func (base *WinningHandBase) finalTurnWinRiverFish() bool {
@@ -353,22 +382,81 @@ type WinningHandNormal struct {
 }
 
 func (hand *WinningHandNormal) allRuns() bool {
-	player := hand.Player
 	if len(hand.XYZs) != 4 || hand.XX[0].IsDragon() {
 		return false
 	}
-	if hand.XX[0].IsActiveWind(player.FieldWind) || hand.XX[0].IsActiveWind(hand.Round.FieldWind) {
+	if hand.XX[0].IsActiveWind(hand.Player.Wind(hand.Round)) || hand.XX[0].IsActiveWind(hand.Round.FieldWind) {
 		return false
 	}
-	has := false
-	for _, xyz := range hand.XYZs {
-		for i, tileType := range xyz {
-			if tileType == player.LastDraw.TileType && i != 1 && !(tileType.Number() == 3 && i == 2) && !(tileType.Number() == 7 && i == 0) {
-				has = true
-			}
+	for _, wait := range hand.waits() {
+		if wait == Ryanmen {
+			return true
+		}
+	}
+	return false
+}
+
+//和了牌を含む面子から考えられる待ち
+func (hand *WinningHandNormal) waits() []WaitType {
+	last := hand.LastTile.TileType
+	waits := make([]WaitType, 0)
+	if hand.XX[0] == last {
+		waits = append(waits, Tanki)
+	}
+	for i, xxx := range hand.XXXs {
+		if xxx[0] == last && hand.isAnKoAnKan(i) == AnKo {
+			waits = append(waits, Shanpon)
+		}
+	}
+	for _, sequential := range hand.Sequential {
+		if !sequential.Concealed {
+			continue
 		}
+		xyz := SortTileTypes(sequential.ToTileType())
+		index, tileType := findTileType(last, [3]TileType{xyz[0], xyz[1], xyz[2]})
+		switch {
+		case tileType == None:
+		case index == 1:
+			waits = append(waits, Kanchan)
+		case index == 0 && tileType.Number() == 7, index == 2 && tileType.Number() == 3:
+			waits = append(waits, Penchan)
+		default:
+			waits = append(waits, Ryanmen)
+		}
+	}
+	return waits
+}
+
+//符が高くなる待ちを優先する、平和は両面
+func (hand *WinningHandNormal) Wait() WaitType {
+	waits := hand.waits()
+	if hand.allRuns() {
+		return Ryanmen
+	}
+	wait := Shanpon
+	for _, w := range waits {
+		switch w {
+		case Kanchan, Penchan, Tanki:
+			return w
+		case Ryanmen:
+			wait = Ryanmen
+		}
+	}
+	if len(waits) == 0 {
+		return Tanki
+	}
+	return wait
+}
+
+func (hand *WinningHandNormal) Shape() HandShape {
+	return HandShape{
+		Tiles:      hand.SortedHandTiles,
+		Head:       hand.Head,
+		Triplets:   hand.Triplets,
+		Sequential: hand.Sequential,
+		Quads:      hand.Quad,
+		Wait:       hand.Wait(),
 	}
-	return has
 }
 
 func (hand *WinningHandNormal) doubleRun() bool {
@@ -479,24 +567,11 @@ func (hand *WinningHandNormal) redDragon() bool {
 }
 
 func (hand *WinningHandNormal) kingsTileDraw() bool {
-	for _, quad := range hand.Player.XXXXs {
-		if quad.Jun == hand.Jun {
-			return true
-		}
-	}
-	return false
+	return hand.selfPick() && hand.Player.rinshan
 }
 
 func (hand *WinningHandNormal) addAQuad() bool {
-	if hand.Atm == hand.Player || hand.Atm == nil {
-		return false
-	}
-	for _, quad := range hand.Atm.XXXXs {
-		if quad.Jun == hand.Jun {
-			return true
-		}
-	}
-	return false
+	return !hand.selfPick() && hand.Chankan
 }
 
 func (hand *WinningHandNormal) threeColorRuns() bool {
@@ -723,101 +798,64 @@ func (hand *WinningHandNormal) allTriples() bool {
 }
 
 func (hand *WinningHandNormal) CountFu(menZen bool) int {
-	fu := 20
+	return Ceil(SumFu(hand.FuItems(menZen)), 10)
+}
+
+func (hand *WinningHandNormal) FuItems(menZen bool) []FuItem {
+	items := []FuItem{{"副底", 20}}
 	tsumo := hand.selfPick()
 
 	if hand.allRuns() {
 		if !menZen {
-			//食い平和
-			return 30
+			return append(items, FuItem{"食い平和", 10})
+		}
+		if !tsumo {
+			return append(items, FuItem{"門前加符", 10})
 		}
-
 		//ツモ平和
-		return fu
+		return items
 	}
 
 	//刻子
+	wait := hand.Wait()
 	for i, xxx := range hand.XXXs {
-		yaochu := xxx[0].IsYaochu()
-		switch hand.isAnKoAnKan(i) {
-		case MinKo:
-			if yaochu {
-				fu += 4
-			} else {
-				fu += 2
-			}
-		case AnKo:
-			if yaochu {
-				fu += 8
-			} else {
-				fu += 4
-			}
-		case MinKan:
-			if yaochu {
-				fu += 16
-			} else {
-				fu += 8
-			}
-		case AnKan:
-			if yaochu {
-				fu += 32
-			} else {
-				fu += 16
-			}
-
+		fuuro := hand.isAnKoAnKan(i)
+		if fuuro == AnKo && wait == Shanpon && !tsumo && xxx[0] == hand.LastTile.TileType {
+			//ロンで完成した刻子は明刻
+			fuuro = MinKo
+		}
+		fu := 2 << uint(fuuro)
+		if xxx[0].IsYaochu() {
+			fu *= 2
 		}
+		items = append(items, FuItem{FuuroNames[fuuro], fu})
 	}
 
 	//雀頭
 	if hand.XX[0].IsDragon() {
-		//三元牌
-		fu += 2
+		items = append(items, FuItem{"役牌雀頭", 2})
 	} else {
-		//場風
 		if hand.XX[0].IsActiveWind(hand.Round.FieldWind) {
-			fu += 2
+			items = append(items, FuItem{"場風雀頭", 2})
 		}
-		//自風
 		if hand.XX[0].IsActiveWind(hand.Player.Wind(hand.Round)) {
-			fu += 2
+			items = append(items, FuItem{"自風雀頭", 2})
 		}
 	}
 
 	//待ち
-	max := 0
-	for _, xyz := range hand.XYZs {
-		index, tileType := findTileType(hand.LastTile.TileType, xyz)
-		if tileType == None {
-			continue
-		}
-		if index == 1 {
-			//嵌張待ち
-			max = 2
-		} else {
-			//辺張待ち
-			if tileType.Number() == 7 && index == 0 {
-				max = 2
-			} else if tileType.Number() == 3 && index == 2 {
-				max = 2
-			}
-		}
-	}
-	fu += max
-
-	if hand.XX[0] == hand.LastTile.TileType {
-		//単騎待ち
-		fu += 2
+	switch wait {
+	case Kanchan, Penchan, Tanki:
+		items = append(items, FuItem{WaitNames[wait] + "待ち", 2})
 	}
 
 	if tsumo {
-		//ツモ符
-		fu += 2
+		items = append(items, FuItem{"ツモ符", 2})
 	} else if menZen {
-		//門前加符
-		fu += 10
+		items = append(items, FuItem{"門前加符", 10})
 	}
 
-	return fu
+	return items
 }
 
 //0:明刻 1:暗刻 2:明槓 3:暗槓
@@ -981,12 +1019,29 @@ func (hand *WinningHand7) CountFu(bool) int {
 	return 25
 }
 
+func (hand *WinningHand7) FuItems(bool) []FuItem {
+	return []FuItem{{"七対子", 25}}
+}
+
+func (hand *WinningHand7) Shape() HandShape {
+	tiles := hand.SortedHandTiles
+	pairs := make([]TilesXX, 0)
+	for i := 0; i+1 < len(tiles); i += 2 {
+		pairs = append(pairs, TilesXX{tiles[i], tiles[i+1]})
+	}
+	return HandShape{Tiles: tiles, Pairs: pairs, Wait: Tanki}
+}
+
 //国士無双の和了形
 type WinningHand13 struct {
 	WinningHandBase
 	Yaochu TileType
 }
 
+func (hand *WinningHand13) Shape() HandShape {
+	return HandShape{Tiles: hand.SortedHandTiles, Wait: Tanki}
+}
+
 type ScoreSrc int
 
 const (
@@ -999,13 +1054,20 @@ const (
 
 //切り上げ満貫
 func NewScore(fu int, fan Fan) ScoreSrc {
+	return newScore(fu, fan, true)
+}
+
+func newScore(fu int, fan Fan, kiriage bool) ScoreSrc {
 	if fan < 5 {
-		if (fan == 3 && fu >= 60) || (fan == 4 && fu >= 30) {
+		if kiriage && ((fan == 3 && fu >= 60) || (fan == 4 && fu >= 30)) {
 			return 満貫
 		} else {
 			for i := 0; i < 2+int(fan); i++ {
 				fu *= 2
 			}
+			if ScoreSrc(fu) > 満貫 {
+				return 満貫
+			}
 			return ScoreSrc(fu)
 		}
 	} else if fan == 5 {
@@ -1021,6 +1083,14 @@ func NewScore(fu int, fan Fan) ScoreSrc {
 	}
 }
 
+var LimitNames = map[ScoreSrc]string{
+	満貫:   "満貫",
+	跳満:   "跳満",
+	倍満:   "倍満",
+	三倍満:  "三倍満",
+	数え役満: "役満",
+}
+
 func (score ScoreSrc) Ceil() int {
 	return Ceil(int(score), 100)
 }
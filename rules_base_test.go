@@ -180,6 +180,29 @@ func Benchmark_thirteenOrphansWin(b *testing.B) {
 	}
 }
 
+func TestWinningHandBase_is7PairsWith4SameWin(t *testing.T) {
+	tests := []struct {
+		name string
+		tt   []TileType
+		want bool
+	}{
+		{"seven pairs", []TileType{Dots1, Dots1, Dots3, Dots3, Dots5, Dots5, Bamboo2, Bamboo2, Bamboo7, Bamboo7, East, East, Red, Red}, true},
+		{"four same", []TileType{Dots1, Dots1, Dots1, Dots1, Dots5, Dots5, Bamboo2, Bamboo2, Bamboo7, Bamboo7, East, East, Red, Red}, true},
+		//鳴いた手はロンの判定で14枚に満たない
+		{"melded", []TileType{Dots1, Dots1, Dots3, Dots3, Dots5, Dots5, Bamboo2, Bamboo2, Bamboo7, Bamboo7, East}, false},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				base := WinningHandBase{SortedTileTypes: SortTileTypes(tt.tt)}
+				if got := base.is7PairsWith4SameWin() != nil; got != tt.want {
+					t.Errorf("is7PairsWith4SameWin() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
 func TestWinningHandNormal_twoDoubleRuns(t *testing.T) {
 	type fields struct {
 		WinningHandBase WinningHandBase
@@ -513,6 +536,29 @@ func TestWinningHandBase_normalWin(t *testing.T) {
 	}
 }
 
+func TestWinningHandBase_oneShot(t *testing.T) {
+	tests := []struct {
+		name    string
+		riichi  Jun
+		ippatsu bool
+		want    bool
+	}{
+		{"no riichi", 0, false, false},
+		{"after riichi", 2, true, true},
+		{"broken", 2, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				base := WinningHandBase{Player: &Player{Riichi: tt.riichi, ippatsu: tt.ippatsu}}
+				if got := base.oneShot(); got != tt.want {
+					t.Errorf("oneShot() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
 func BenchmarkWinningHandBase_normalWin(b *testing.B) {
 	tts := []TileType{Dots1, Dots1, Dots1, Dots2, Dots2, Dots2, Dots3, Dots3, Dots3, Dots4, Dots4, Dots4, Dots5, Dots5}
 	tiles := toSampleTiles(tts)
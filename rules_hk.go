@@ -0,0 +1,465 @@
+package mahjong
+
+import "errors"
+
+//香港麻将(古例)、番数で倍々に払い爆棚で頭打ち、花牌は自風の花で一番
+//立直・ドラ・フリテンはなく、親の和了と荒牌は連荘
+type HongKongRule struct {
+	BaseRule
+	Options *HongKongOptions
+}
+
+//香港麻将のルール設定
+type HongKongOptions struct {
+	//起糊、花の番も数える
+	MinimumFaan Fan
+	//爆棚、これ以上の番数は同じ点数
+	MaxFaan Fan
+	//零番で放銃者以外が払う点
+	BasePoints int
+	//全銃、ロンは放銃者が三人分を払う、なしなら半銃
+	DiscarderPaysAll bool
+}
+
+//三番起糊、十番爆棚の半銃
+var DefaultHongKongOptions = HongKongOptions{
+	MinimumFaan: 3,
+	MaxFaan:     10,
+	BasePoints:  1,
+}
+
+//未設定ならDefaultHongKongOptions
+func (rule HongKongRule) options() *HongKongOptions {
+	if rule.Options == nil {
+		return &DefaultHongKongOptions
+	}
+	return rule.Options
+}
+
+//爆棚の役、番数はMaxFaan
+const (
+	hkThirteenOrphans    = "十三么"
+	hkNineGates          = "九子連環"
+	hkAllHonors          = "字一色"
+	hkAllTerminals       = "清么九"
+	hkBigFourWinds       = "大四喜"
+	hkSmallFourWinds     = "小四喜"
+	hkFourConcealedPungs = "坎坎糊"
+	hkFourKongs          = "十八羅漢"
+	hkHeavenlyHand       = "天糊"
+	hkEightImmortals     = "八仙過海"
+)
+
+var hkLimitHands = map[string]bool{
+	hkThirteenOrphans: true, hkNineGates: true, hkAllHonors: true, hkAllTerminals: true, hkBigFourWinds: true,
+	hkSmallFourWinds: true, hkFourConcealedPungs: true, hkFourKongs: true, hkHeavenlyHand: true,
+}
+
+//和了形ごとの番、花牌は含めない
+func (rule HongKongRule) faans(hand *WinningHandNormal, tileTypes []TileType) []Yaku {
+	limit := rule.options().MaxFaan
+	player := hand.Player
+	concealed := player.Concealed()
+	yakuTachi := make([]Yaku, 0)
+	add := func(name string, fan Fan) {
+		yakuTachi = append(yakuTachi, Yaku{Name: name, FanFR: fan, FanMZ: fan})
+	}
+
+	var suits [3]bool
+	honors, yaochu, terminals := false, true, true
+	for _, tileType := range tileTypes {
+		yaochu = yaochu && tileType.IsYaochu()
+		terminals = terminals && tileType.IsTerminals()
+		if tileType.IsSuit() {
+			suits[tileType.Suit()] = true
+		} else {
+			honors = true
+		}
+	}
+	nSuits := 0
+	for _, suit := range suits {
+		if suit {
+			nSuits++
+		}
+	}
+	winds, dragons := 0, 0
+	for _, xxx := range hand.XXXs {
+		switch {
+		case xxx[0].IsWind():
+			winds++
+		case xxx[0].IsDragon():
+			dragons++
+		}
+	}
+	concealedPungs := hand.allTripletHand() && concealed && (hand.Tsumo || hand.XX[0] == hand.LastTile.TileType)
+
+	//爆棚は他の番と複合しない
+	switch {
+	case hand.heavenlyHand():
+		add(hkHeavenlyHand, limit)
+	case isNineGates(hand.SortedTileTypes, hand.LastTile.TileType) && concealed:
+		add(hkNineGates, limit)
+	case nSuits == 0:
+		add(hkAllHonors, limit)
+	case terminals:
+		add(hkAllTerminals, limit)
+	case winds == 4:
+		add(hkBigFourWinds, limit)
+	case winds == 3 && hand.XX[0].IsWind():
+		add(hkSmallFourWinds, limit)
+	case len(player.XXXXs) == 4:
+		add(hkFourKongs, limit)
+	case concealedPungs:
+		add(hkFourConcealedPungs, limit)
+	}
+	if len(yakuTachi) != 0 {
+		return yakuTachi
+	}
+
+	if hand.Tsumo {
+		add("自摸", 1)
+	}
+	if concealed {
+		add("門前清", 1)
+	}
+	switch {
+	case len(hand.XYZs) == 4:
+		add("平糊", 1)
+	case hand.allTripletHand():
+		add("對對糊", 3)
+	}
+	switch {
+	case nSuits == 1 && honors:
+		add("混一色", 3)
+	case nSuits == 1:
+		add("清一色", 7)
+	}
+	if yaochu {
+		add("混么九", 1)
+	}
+	switch {
+	case dragons == 3:
+		add("大三元", 8)
+	case dragons == 2 && hand.XX[0].IsDragon():
+		add("小三元", 5)
+	case dragons > 0:
+		add("三元牌", Fan(dragons))
+	}
+	if hand.playerWind() {
+		add("門風", 1)
+	}
+	if hand.prevailingWind() {
+		add("圈風", 1)
+	}
+	if hand.finalTurnWinSeaMoon() {
+		add("海底撈月", 1)
+	}
+	if hand.kingsTileDraw() {
+		add("槓上開花", 1)
+	}
+	if hand.addAQuad() {
+		add("搶槓", 1)
+	}
+	return yakuTachi
+}
+
+//花牌の番、自風と同じ数の花と季で一番ずつ、花か季の四枚が揃えばその代わりに一台花
+func (rule HongKongRule) flowerFaans(player *Player) []Yaku {
+	yakuTachi := make([]Yaku, 0)
+	add := func(name string, fan Fan) {
+		yakuTachi = append(yakuTachi, Yaku{Name: name, FanFR: fan, FanMZ: fan})
+	}
+	switch len(player.Flowers) {
+	case 0:
+		add("無花", 1)
+		return yakuTachi
+	case 8:
+		add(hkEightImmortals, rule.options().MaxFaan)
+		return yakuTachi
+	}
+	wind := player.Wind(rule.Maj.Round)
+	var sets [2]int
+	var seat [2]bool
+	for _, flower := range player.Flowers {
+		n := flower.TileType - PlumBlossom
+		sets[n/4]++
+		if FieldWind(n%4) == wind {
+			seat[n/4] = true
+		}
+	}
+	for i, n := range sets {
+		switch {
+		case n == 4:
+			add("一台花", 2)
+		case seat[i]:
+			add("正花", 1)
+		}
+	}
+	return yakuTachi
+}
+
+func (rule HongKongRule) PlayersSitDown() Players {
+	players := NewPlayers(4)
+	for i := 0; i < 4; i++ {
+		*players.Now() = Player{FieldWind: FieldWind(i), Tiles: make([]Tile, 0)}
+		players.ToNext()
+	}
+	return players
+}
+
+func (HongKongRule) Tiles() []Tile {
+	return tilesWithFlowers()
+}
+
+func (HongKongRule) MaxRound() *Round {
+	return NewRound(PeiBa, 4)
+}
+
+//王牌はなく、槓と花牌の補充の分だけ山の端から減る
+func (rule HongKongRule) WallTilesCannotDraw() uint8 {
+	return uint8(rule.Maj.replacements())
+}
+
+func (HongKongRule) CanRiichi(*Player) ([]Tile, error) {
+	return nil, errors.New("Hong Kong mahjong has no riichi. ")
+}
+
+func (HongKongRule) Riichi(*Player, Tile) error {
+	return errors.New("Hong Kong mahjong has no riichi. ")
+}
+
+func (HongKongRule) CanOpenRiichi(*Player) ([]Tile, error) {
+	return nil, errors.New("Hong Kong mahjong has no riichi. ")
+}
+
+func (HongKongRule) OpenRiichi(*Player, Tile) error {
+	return errors.New("Hong Kong mahjong has no riichi. ")
+}
+
+//暗槓は十三么のみ搶槓できる
+func (rule HongKongRule) CanRon(player *Player) ([]Agari, error) {
+	maj := rule.Maj
+	if err := maj.checkRonTile(player); err != nil {
+		return nil, err
+	}
+	agaris, err := rule.CanAgari(player, maj.LastTile)
+	if err != nil {
+		return nil, err
+	}
+	if maj.Chankan != nil && maj.Chankan.Concealed {
+		for _, agari := range agaris {
+			if agari.YakuTachi[0].Name == hkThirteenOrphans {
+				return []Agari{agari}, nil
+			}
+		}
+		return nil, errors.New("Only thirteen orphans can rob a concealed kong. ")
+	}
+	return agaris, nil
+}
+
+func (rule HongKongRule) Ron(player *Player) (*WinResult, error) {
+	agaris, err := rule.CanRon(player)
+	if err != nil {
+		return nil, err
+	}
+	result := rule.newWinResult(player, rule.Maj.LastTilePlayer, rule.Maj.LastTile, agaris)
+	rule.pay(result)
+	return result, nil
+}
+
+//截糊、放銃者の下家から一人だけ
+func (rule HongKongRule) CanMultiRon(players []*Player) error {
+	if len(players) != 1 {
+		return errors.New("multiple ron is not allowed")
+	}
+	_, err := rule.CanRon(players[0])
+	return err
+}
+
+func (rule HongKongRule) CanTsumo(player *Player) ([]Agari, error) {
+	return rule.CanAgari(player, Tile{})
+}
+
+func (rule HongKongRule) Tsumo(player *Player) (*WinResult, error) {
+	agaris, err := rule.CanTsumo(player)
+	if err != nil {
+		return nil, err
+	}
+	result := rule.newWinResult(player, nil, player.LastDraw, agaris)
+	rule.pay(result)
+	return result, nil
+}
+
+//零番の点を番数だけ倍にする、爆棚で頭打ち
+func (rule HongKongRule) Points(fan Fan) int {
+	options := rule.options()
+	if fan > options.MaxFaan {
+		fan = options.MaxFaan
+	}
+	return options.BasePoints << uint(fan)
+}
+
+//ツモは全員が倍、ロンは放銃者が倍で他の二人は一倍、全銃なら放銃者が全て払う
+func (rule HongKongRule) pay(result *WinResult) {
+	winner := result.Winner
+	points := rule.Points(result.Fan)
+	discarder := result.Discarder
+	rule.Maj.Players.Do(
+		func(p *Player) {
+			if p == winner {
+				return
+			}
+			s := points
+			if result.Tsumo || p == discarder {
+				s *= 2
+			}
+			payer := p
+			if !result.Tsumo && rule.options().DiscarderPaysAll {
+				payer = discarder
+			}
+			result.Deltas[payer.FieldWind] -= s
+			result.Deltas[winner.FieldWind] += s
+		},
+	)
+	rule.Maj.Players.Do(
+		func(p *Player) {
+			p.Score += result.Deltas[p.FieldWind]
+		},
+	)
+}
+
+//最も番数の多い分解を選ぶ
+func (rule HongKongRule) newWinResult(winner, discarder *Player, tile Tile, agaris []Agari) *WinResult {
+	agari := agaris[0]
+	for _, a := range agaris[1:] {
+		if a.Fan(true) > agari.Fan(true) {
+			agari = a
+		}
+	}
+	result := &WinResult{
+		Winner:      winner,
+		Discarder:   discarder,
+		Tsumo:       discarder == nil,
+		WinningTile: tile,
+		Hand:        agari.Shape,
+		Fan:         agari.Fan(true),
+	}
+	for _, yaku := range agari.YakuTachi {
+		result.YakuTachi = append(result.YakuTachi, YakuFan{yaku.Name, yaku.FanMZ})
+	}
+	if result.Fan >= rule.options().MaxFaan {
+		result.Limit = "爆棚"
+	}
+	return result
+}
+
+func (HongKongRule) CanNineYaochus(*Player) error {
+	return errors.New("Hong Kong mahjong has no abortive draw. ")
+}
+
+func (HongKongRule) NineYaochus(*Player) (*RyuukyokuResult, error) {
+	return nil, errors.New("Hong Kong mahjong has no abortive draw. ")
+}
+
+func (HongKongRule) TripleRon([]*Player) (*RyuukyokuResult, error) {
+	return nil, errors.New("Hong Kong mahjong has no abortive draw. ")
+}
+
+//起糊に満たない和了はできない
+func (rule HongKongRule) CanAgari(player *Player, last Tile) ([]Agari, error) {
+	if player.handSize(last) != 14 {
+		return nil, errors.New("Player does not have 14 tiles. ")
+	}
+	tiles := append([]Tile(nil), player.Tiles...)
+	if last.TileType != None {
+		tiles = append(tiles, last)
+	}
+	base := rule.Maj.NewWinningHandBase(player, rule.Maj.Players.Now(), SortTiles(tiles))
+	base.Tsumo = last.TileType == None
+	base.LastTile = last
+	if base.Tsumo {
+		base.LastTile = player.LastDraw
+	}
+	tileTypes := toTileTypes(player.AllTiles())
+	if last.TileType != None {
+		tileTypes = append(tileTypes, last.TileType)
+	}
+	flowers := rule.flowerFaans(player)
+	shapes := make([]Agari, 0)
+	for _, hand := range base.normalWin() {
+		shapes = append(shapes, Agari{YakuTachi: rule.faans(hand, tileTypes), Shape: hand.Shape()})
+	}
+	if hand13 := base.thirteenOrphansWin(); hand13 != nil {
+		yaku := Yaku{Name: hkThirteenOrphans, FanFR: rule.options().MaxFaan, FanMZ: rule.options().MaxFaan}
+		shapes = append(shapes, Agari{YakuTachi: []Yaku{yaku}, Shape: hand13.Shape()})
+	}
+	if len(shapes) == 0 {
+		return nil, errors.New("Player cannot agari. ")
+	}
+	agaris := make([]Agari, 0)
+	for _, agari := range shapes {
+		//爆棚の役に花の番は足さない
+		if !hkLimitHands[agari.YakuTachi[0].Name] {
+			agari.YakuTachi = append(agari.YakuTachi, flowers...)
+		}
+		if agari.Fan(true) >= rule.options().MinimumFaan {
+			agaris = append(agaris, agari)
+		}
+	}
+	if len(agaris) == 0 {
+		return nil, errors.New("Less than the minimum faan. ")
+	}
+	return agaris, nil
+}
+
+func (HongKongRule) DoraIndicators() []Tile {
+	return nil
+}
+
+//親の和了と荒牌は連荘
+func (rule HongKongRule) Renchan() bool {
+	result := rule.Maj.Result
+	parent := rule.Maj.Players.Parent(rule.Maj.Round)
+	switch result.ResultType {
+	case AgariResult:
+		for _, win := range result.Wins {
+			if win.Winner == parent {
+				return true
+			}
+		}
+	case DrawResult:
+		return true
+	}
+	return false
+}
+
+func (rule HongKongRule) IsGameOver(renchan bool) bool {
+	return rule.Maj.Round.IsAllLast() && !renchan
+}
+
+//順位点はなく、点数がそのまま
+func (HongKongRule) Settle(placements []Placement) {
+	for i := range placements {
+		placements[i].Point = float64(placements[i].Score)
+	}
+}
+
+//荒牌は点数の移動なし
+func (rule HongKongRule) CanRyuukyoku() (DrawType, error) {
+	maj := rule.Maj
+	if maj.Players.Now().Phase.Check(AddTile) != nil {
+		return 0, errors.New("Not before drawing. ")
+	}
+	if maj.RemainderTilesCanDraw() == 0 {
+		return DrawExhaustive, nil
+	}
+	return 0, errors.New("Can not ryuukyoku. ")
+}
+
+func (rule HongKongRule) Ryuukyoku() (*RyuukyokuResult, error) {
+	kind, err := rule.CanRyuukyoku()
+	if err != nil {
+		return nil, err
+	}
+	return &RyuukyokuResult{Type: kind}, nil
+}
@@ -0,0 +1,171 @@
+package mahjong
+
+import "testing"
+
+func newTestHongKong(options *HongKongOptions) (*Mahjong, HongKongRule) {
+	maj := newTestFlowerGame(&HongKongRule{Options: options})
+	return maj, *maj.Rule.(*HongKongRule)
+}
+
+func TestHongKongRule_CanAgari(t *testing.T) {
+	testFlowerAgaris(
+		t, func() Rule {
+			return &HongKongRule{}
+		}, []flowerAgariTest{
+			{
+				"all pungs", "3336669m p111z p222z", "9m", false, "", 9,
+				[]string{"圈風", "對對糊", "混一色", "無花", "門風"},
+			},
+			//南家の花は蘭と夏
+			{
+				"seat flowers", "3336669m p111z p222z", "9m", false, "26f", 10,
+				[]string{"圈風", "對對糊", "正花", "正花", "混一色", "門風"},
+			},
+			{
+				"other flowers", "3336669m p111z p222z", "9m", false, "13f", 8,
+				[]string{"圈風", "對對糊", "混一色", "門風"},
+			},
+			{
+				"flower set", "3336669m p111z p222z", "9m", false, "12347f", 10,
+				[]string{"一台花", "圈風", "對對糊", "混一色", "門風"},
+			},
+			{
+				"concealed chows", "12346789p234s88m", "5p", true, "", 4,
+				[]string{"平糊", "無花", "自摸", "門前清"},
+			},
+			{
+				"dragons", "555z66z77z789s c123s", "7z", false, "", 9,
+				[]string{"小三元", "混一色", "無花"},
+			},
+			{"thirteen orphans", "19m19p19s1234567z", "1m", false, "2f", 10, []string{"十三么"}},
+			{"big four winds", "44z55m p111z p222z p333z", "4z", true, "", 10, []string{"大四喜"}},
+			{"four concealed pungs", "111m222p333s4445z", "5z", false, "", 10, []string{"坎坎糊"}},
+		},
+	)
+}
+
+func TestHongKongRule_CanAgari_Minimum(t *testing.T) {
+	maj, rule := newTestHongKong(nil)
+	player := maj.Players.FindField(SouthField)
+	hand, _ := ParseHand("234m567m345p67s99s")
+	player.SetHand(hand)
+	//門前清・平糊・無花で三番
+	if _, err := rule.CanAgari(player, Tile{TileType: Bamboo8}); err != nil {
+		t.Error(err)
+	}
+	//自風でない花は無花を消すだけ
+	player.Flowers = toSampleTiles([]TileType{PlumBlossom})
+	if agaris, err := rule.CanAgari(player, Tile{TileType: Bamboo8}); err == nil {
+		t.Errorf("CanAgari() = %+v, want error", agaris)
+	}
+}
+
+func TestHongKongRule_CanAgari_TileCount(t *testing.T) {
+	maj, rule := newTestHongKong(nil)
+	player := maj.Players.FindField(SouthField)
+	hand, _ := ParseHand("123456789m1p")
+	player.SetHand(hand)
+	//番数が足りないのではなく枚数で弾く
+	_, err := rule.CanAgari(player, Tile{TileType: Dots1})
+	if err == nil || err.Error() != "Player does not have 14 tiles. " {
+		t.Errorf("CanAgari() error = %v", err)
+	}
+}
+
+func TestHongKongRule_Ron(t *testing.T) {
+	capped := DefaultHongKongOptions
+	capped.MaxFaan = 5
+	paysAll := DefaultHongKongOptions
+	paysAll.DiscarderPaysAll = true
+	tests := []struct {
+		name    string
+		options *HongKongOptions
+		limit   string
+		want    [4]int
+	}{
+		{"everyone pays", nil, "", [4]int{-512, 2048, -512, -1024}},
+		{"discarder pays all", &paysAll, "", [4]int{0, 2048, 0, -2048}},
+		{"capped", &capped, "爆棚", [4]int{-32, 128, -32, -64}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj, rule := newTestHongKong(tt.options)
+				winner := maj.Players.FindField(SouthField)
+				hand, _ := ParseHand("3336669m p111z p222z")
+				winner.SetHand(hand)
+				discarder := maj.Players.FindField(NorthField)
+				maj.LastTile = Tile{TileType: Characters9, Id: 3}
+				maj.LastTilePlayer = discarder
+				maj.Players.Now().Phase = AddTile
+
+				result, err := rule.Ron(winner)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Fan != 9 || result.Limit != tt.limit || result.Deltas != tt.want {
+					t.Errorf("Ron() = %v %q %v, want 9 %q %v", result.Fan, result.Limit, result.Deltas, tt.limit, tt.want)
+				}
+				if winner.Score != tt.want[SouthField] {
+					t.Errorf("Score = %v, want %v", winner.Score, tt.want[SouthField])
+				}
+			},
+		)
+	}
+}
+
+func TestHongKongRule_Tsumo(t *testing.T) {
+	paysAll := DefaultHongKongOptions
+	paysAll.DiscarderPaysAll = true
+	maj, rule := newTestHongKong(&paysAll)
+	winner := maj.Players.FindField(EastField)
+	hand, _ := ParseHand("12346789p234s88m")
+	winner.SetHand(hand)
+	winner.Tiles = append(winner.Tiles, Tile{TileType: Dots5, Id: 1})
+	winner.LastDraw = winner.Tiles[len(winner.Tiles)-1]
+
+	result, err := rule.Tsumo(winner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//ツモは全銃でも全員が払う
+	want := [4]int{96, -32, -32, -32}
+	if result.Fan != 4 || result.Deltas != want {
+		t.Errorf("Tsumo() = %v %v, want 4 %v", result.Fan, result.Deltas, want)
+	}
+}
+
+func TestHongKongRule_Renchan(t *testing.T) {
+	maj, rule := newTestHongKong(nil)
+	parent := maj.Players.Parent(maj.Round)
+	child := maj.Players.FindField(SouthField)
+	tests := []struct {
+		name   string
+		result Result
+		want   bool
+	}{
+		{"parent wins", Result{ResultType: AgariResult, Wins: []*WinResult{{Winner: parent}}}, true},
+		{"child wins", Result{ResultType: AgariResult, Wins: []*WinResult{{Winner: child}}}, false},
+		{"exhaustive draw", Result{ResultType: DrawResult, Ryuukyoku: &RyuukyokuResult{Type: DrawExhaustive}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj.Result = tt.result
+				if got := rule.Renchan(); got != tt.want {
+					t.Errorf("Renchan() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestHongKongRule_Simulate(t *testing.T) {
+	newRule := func() Rule {
+		return &HongKongRule{}
+	}
+	stats := simulateFlowerRule(t, newRule)
+	if stats.Hands < 16 {
+		t.Errorf("hands = %v, want at least 16", stats.Hands)
+	}
+}
@@ -1,17 +1,31 @@
 package mahjong
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+)
 
 type JapaneseBaseRule struct {
 	BaseRule
+	Options *RuleOptions
 }
 
-func (JapaneseBaseRule) TileAmount() uint8 {
+//三人麻雀は二萬から八萬を抜く
+func (rule JapaneseBaseRule) TileAmount() uint8 {
+	if rule.sanma() {
+		return 108
+	}
 	return 136
 }
 
+//席の数で三人麻雀かどうかを決める
+func (rule JapaneseBaseRule) sanma() bool {
+	return rule.Maj != nil && rule.Maj.Players.ring != nil && rule.Maj.Players.Len() == 3
+}
+
+//王牌は槓と抜きドラの分だけ海底側へ延びる
 func (rule JapaneseBaseRule) WallTilesCannotDraw() uint8 {
-	return 14 + rule.Maj.KanCount
+	return 14 + rule.Maj.KanCount + uint8(rule.Maj.KitaCount())
 }
 
 func (rule JapaneseBaseRule) WallLastTile() Tile {
@@ -23,6 +37,13 @@ func (JapaneseBaseRule) RyanShanAmount() uint8 {
 }
 
 func (rule JapaneseBaseRule) CanRiichi(player *Player) ([]Tile, error) {
+	if err := rule.canDeclareRiichi(player); err != nil {
+		return nil, err
+	}
+	//一向聴以上なら何を切っても聴牌しない
+	if player.Shanten().Min() > 0 {
+		return nil, errors.New("Player can not riichi. ")
+	}
 	tiles := make([]Tile, 0)
 	for tileType := Dots1; tileType <= Red; tileType++ {
 		if discard, err := rule.canRiichi(player, tileType); err == nil {
@@ -49,12 +70,7 @@ func (rule JapaneseBaseRule) canRiichi(player *Player, add TileType) ([]Tile, er
 		removed := make([]Tile, len(player.Tiles))
 		copy(removed, player.Tiles)
 		removed[i] = Tile{TileType: add}
-		removed = SortTiles(removed)
-		base := rule.Maj.NewWinningHandBase(player, nil, removed)
-		baseWin := base.normalWin()
-		_7Win := base.is7PairsWin()
-		_13Win := base.thirteenOrphansWin()
-		if baseWin != nil || _7Win != nil || _13Win != nil {
+		if rule.isWinningShape(player, removed) {
 			tiles = append(tiles, t)
 		}
 	}
@@ -64,7 +80,32 @@ func (rule JapaneseBaseRule) canRiichi(player *Player, add TileType) ([]Tile, er
 	return nil, errors.New("Player can not riichi. ")
 }
 
+//立直棒
+const RiichiDeposit = 1000
+
+//積み棒一本の払い、ロンは放銃者が、ツモは一人ずつ払う、三人麻雀でも同じ
+const (
+	HonbaRon   = 300
+	HonbaTsumo = 100
+)
+
+func (rule JapaneseBaseRule) canDeclareRiichi(player *Player) error {
+	if !player.Riichi.First() {
+		return errors.New("Player has declared riichi. ")
+	}
+	if !player.Concealed() {
+		return errors.New("Player is not concealed. ")
+	}
+	if player.Score < RiichiDeposit {
+		return errors.New("Player has not enough points to riichi. ")
+	}
+	return nil
+}
+
 func (rule JapaneseBaseRule) Riichi(player *Player, tile Tile) error {
+	if err := rule.canDeclareRiichi(player); err != nil {
+		return err
+	}
 	index, err := rule.Maj.CanDahai(player, tile)
 	if err != nil {
 		return err
@@ -74,12 +115,7 @@ func (rule JapaneseBaseRule) Riichi(player *Player, tile Tile) error {
 		removed := make([]Tile, len(player.Tiles))
 		copy(removed, player.Tiles)
 		removed[index] = Tile{TileType: tileType}
-		removed = SortTiles(removed)
-		base := rule.Maj.NewWinningHandBase(player, nil, removed)
-		baseWin := base.normalWin()
-		_7Win := base.is7PairsWin()
-		_13Win := base.thirteenOrphansWin()
-		if baseWin != nil || _7Win != nil || _13Win != nil {
+		if rule.isWinningShape(player, removed) {
 			ok = true
 			break
 		}
@@ -88,101 +124,375 @@ func (rule JapaneseBaseRule) Riichi(player *Player, tile Tile) error {
 		return errors.New("cannot riichi")
 	}
 	rule.Maj.dahai(player, index)
-	player.Riichi = rule.Maj.Jun()
-	rule.Maj.Output("Riichi")
+	player.Discards[len(player.Discards)-1].Riichi = true
+	player.Riichi = player.jun
+	player.ippatsu = true
+	player.Score -= RiichiDeposit
+	rule.Maj.Deposit += RiichiDeposit
+	return nil
+}
+
+func (rule JapaneseBaseRule) CanOpenRiichi(player *Player) ([]Tile, error) {
+	if !rule.options().OpenRiichi {
+		return nil, errors.New("open riichi is not allowed")
+	}
+	return rule.CanRiichi(player)
+}
+
+//手牌を公開する立直
+func (rule JapaneseBaseRule) OpenRiichi(player *Player, tile Tile) error {
+	if !rule.options().OpenRiichi {
+		return errors.New("open riichi is not allowed")
+	}
+	if err := rule.Riichi(player, tile); err != nil {
+		return err
+	}
+	player.OpenRiichi = true
 	return nil
 }
 
+//立直宣言牌で放銃したら立直は不成立
+func (rule JapaneseBaseRule) cancelRiichi(discarder *Player) {
+	discards := discarder.Discards
+	if len(discards) == 0 || !discards[len(discards)-1].Riichi || discards[len(discards)-1].Tile != rule.Maj.LastTile {
+		return
+	}
+	discards[len(discards)-1].Riichi = false
+	discarder.Riichi = 0
+	discarder.OpenRiichi = false
+	discarder.Score += RiichiDeposit
+	rule.Maj.Deposit -= RiichiDeposit
+}
+
+//積み棒と供託
+func (rule JapaneseBaseRule) payBonus(result *WinResult, payers ...*Player) {
+	each := int(rule.Maj.Round.Honba) * HonbaTsumo
+	if result.Discarder != nil {
+		each = int(rule.Maj.Round.Honba) * HonbaRon
+	}
+	for _, payer := range payers {
+		result.Deltas[payer.FieldWind] -= each
+		result.Deltas[result.Winner.FieldWind] += each
+		result.Honba += each
+	}
+	result.Deposit = rule.Maj.Deposit
+	result.Deltas[result.Winner.FieldWind] += rule.Maj.Deposit
+	rule.Maj.Deposit = 0
+}
+
 func (rule JapaneseBaseRule) CanRon(player *Player) ([]Agari, error) {
+	maj := rule.Maj
+	if maj.LastTilePlayer == player || maj.LastTile.TileType == None {
+		return nil, errors.New("No tile to ron. ")
+	}
+	agaris, err := rule.CanAgari(player, maj.LastTile)
+	if err != nil {
+		return nil, err
+	}
 	if rule.FuriTen(player) {
 		return nil, errors.New("FuriTen")
 	}
-	if rule.Maj.LastTilePlayer == player {
-		return nil, errors.New("")
+	//暗槓は国士無双のみ搶槓できる
+	if maj.Chankan != nil && maj.Chankan.Concealed {
+		for _, agari := range agaris {
+			if agari.YakuTachi[0].Name == "国士無双" {
+				return []Agari{agari}, nil
+			}
+		}
+		return nil, errors.New("Only kokushi can rob an ankan. ")
 	}
-	return rule.CanAgari(player, rule.Maj.LastTile)
+	return agaris, nil
 }
 
-func (rule JapaneseBaseRule) Ron(player *Player) error {
+func (rule JapaneseBaseRule) Ron(player *Player) (*WinResult, error) {
 	agaris, err := rule.CanRon(player)
 	if err != nil {
-		return err
-	}
-	menZen := player.Concealed()
-	maxScoreSrc := ScoreSrc(0)
-	var result Agari
-	for _, agari := range agaris {
-		if src := NewScore(agari.Fu, CountFan(agari.YakuTachi, menZen)); src > maxScoreSrc {
-			maxScoreSrc = src
-			result = agari
-		}
-	}
-	rule.Maj.Output("Ron!", "Base score:", maxScoreSrc)
-	for _, yaku := range result.YakuTachi {
-		rule.Maj.Output(yaku.Name)
+		return nil, err
 	}
+	discarder := rule.Maj.LastTilePlayer
+	rule.cancelRiichi(discarder)
+	result := rule.NewWinResult(player, discarder, rule.Maj.LastTile, agaris)
+	s := result.Score.ChildRon()
 	if player.IsParent(rule.Maj.Round) {
-		s := maxScoreSrc.ParentRon()
-		player.Score += s
-		rule.Maj.LastTilePlayer.Score -= s
-	} else {
-		s := maxScoreSrc.ChildRon()
-		player.Score += s
-		rule.Maj.LastTilePlayer.Score -= s
+		s = result.Score.ParentRon()
 	}
-	return nil
+	result.Deltas[player.FieldWind] += s
+	result.Deltas[discarder.FieldWind] -= s
+	//ダブロンの積み棒と供託は上家取り
+	if len(rule.Maj.Result.Wins) == 0 {
+		rule.payBonus(result, discarder)
+	}
+	rule.pay(result)
+	return result, nil
 }
 
 func (rule JapaneseBaseRule) CanTsumo(player *Player) ([]Agari, error) {
 	return rule.CanAgari(player, Tile{})
 }
 
-func (rule JapaneseBaseRule) Tsumo(player *Player) error {
+func (rule JapaneseBaseRule) Tsumo(player *Player) (*WinResult, error) {
 	agaris, err := rule.CanTsumo(player)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	menZen := player.Concealed()
-	maxScoreSrc := ScoreSrc(0)
-	for _, agari := range agaris {
-		if src := NewScore(agari.Fu, CountFan(agari.YakuTachi, menZen)); src > maxScoreSrc {
-			maxScoreSrc = src
-		}
-	}
-	rule.Maj.Output("Tsumo!", "Base score:", maxScoreSrc)
+	result := rule.NewWinResult(player, nil, player.LastDraw, agaris)
+	payers := make([]*Player, 0)
 	round := rule.Maj.Round
+	//三人麻雀でいない北家の分
+	missing := 0
 	if player.IsParent(round) {
-		s := maxScoreSrc.ParentTsumo()
-		player.Score += s * 3
+		s := result.Score.ParentTsumo()
+		missing = s
 		rule.Maj.Players.Do(
 			func(p *Player) {
 				if p != player {
-					p.Score -= s
+					result.Deltas[p.FieldWind] -= s
+					result.Deltas[player.FieldWind] += s
+					payers = append(payers, p)
 				}
 			},
 		)
 	} else {
-		child, parent := maxScoreSrc.ChildTsumo()
-		player.Score += child*2 + parent
+		child, parent := result.Score.ChildTsumo()
+		missing = child
 		rule.Maj.Players.Do(
 			func(p *Player) {
 				if p == player {
 					return
 				}
+				s := child
 				if p.IsParent(round) {
-					p.Score -= parent
-				} else {
-					p.Score -= child
+					s = parent
 				}
+				result.Deltas[p.FieldWind] -= s
+				result.Deltas[player.FieldWind] += s
+				payers = append(payers, p)
 			},
 		)
 	}
-	return nil
+	if rule.sanma() && rule.options().NorthBisection {
+		rule.bisectNorth(result, missing, payers)
+	}
+	rule.payBonus(result, payers...)
+	rule.pay(result)
+	return result, nil
+}
+
+//北家折半、なければツモ損
+func (rule JapaneseBaseRule) bisectNorth(result *WinResult, missing int, payers []*Player) {
+	each := Ceil(missing/len(payers), 100)
+	for _, payer := range payers {
+		result.Deltas[payer.FieldWind] -= each
+		result.Deltas[result.Winner.FieldWind] += each
+	}
+}
+
+//最も高い和了形を選ぶ
+func (rule JapaneseBaseRule) NewWinResult(winner, discarder *Player, tile Tile, agaris []Agari) *WinResult {
+	menZen := winner.Concealed()
+	maxScoreSrc := ScoreSrc(0)
+	var agari Agari
+	for _, a := range agaris {
+		if src := rule.score(a, menZen); src > maxScoreSrc {
+			maxScoreSrc = src
+			agari = a
+		}
+	}
+
+	result := &WinResult{
+		Winner:      winner,
+		Discarder:   discarder,
+		Tsumo:       discarder == nil,
+		WinningTile: tile,
+		Hand:        agari.Shape,
+		Fan:         agari.Fan(menZen),
+		Fu:          agari.Fu,
+		FuItems:     agari.FuItems,
+		Score:       maxScoreSrc,
+		Limit:       LimitNames[maxScoreSrc],
+	}
+	yakuMan := false
+	for _, yaku := range agari.YakuTachi {
+		fan := yaku.FanFR
+		if menZen {
+			fan = yaku.FanMZ
+		}
+		if fan >= 役満 {
+			yakuMan = true
+		}
+		result.YakuTachi = append(result.YakuTachi, YakuFan{yaku.Name, fan})
+	}
+	if maxScoreSrc == 数え役満 && !yakuMan {
+		result.Limit = "数え役満"
+	}
+	if times := int(maxScoreSrc / 数え役満); yakuMan && times > 1 {
+		result.Limit = strconv.Itoa(times) + "倍役満"
+	}
+	if !yakuMan {
+		doras := []YakuFan{{"ドラ", agari.Dora}, {"裏ドラ", agari.UraDora}, {"赤ドラ", agari.AkaDora}, {"抜きドラ", agari.NukiDora}}
+		for _, dora := range doras {
+			if dora.Fan > 0 {
+				result.YakuTachi = append(result.YakuTachi, dora)
+			}
+		}
+	}
+	return result
+}
+
+//切り上げ満貫と役満の複合
+func (rule JapaneseBaseRule) score(agari Agari, menZen bool) ScoreSrc {
+	options := rule.options()
+	fan := agari.Fan(menZen)
+	if fan >= 役満 && CountFan(agari.YakuTachi, menZen) >= 役満 && options.DoubleYakuman {
+		return 数え役満 * ScoreSrc(fan/役満)
+	}
+	return newScore(agari.Fu, fan, options.KiriageMangan)
+}
+
+func (rule JapaneseBaseRule) pay(result *WinResult) {
+	rule.Maj.Players.Do(
+		func(p *Player) {
+			p.Score += result.Deltas[p.FieldWind]
+		},
+	)
+}
+
+//役の有無を問わない和了形
+func (rule JapaneseBaseRule) isWinningShape(player *Player, tiles []Tile) bool {
+	base := rule.Maj.NewWinningHandBase(player, nil, SortTiles(tiles))
+	return base.normalWin() != nil || base.is7PairsWin() != nil || base.thirteenOrphansWin() != nil
+}
+
+//待ち牌
+func (rule JapaneseBaseRule) WaitingTileTypes(player *Player) []TileType {
+	waits := make([]TileType, 0)
+	for tileType := Dots1; tileType <= Red; tileType++ {
+		tiles := make([]Tile, len(player.Tiles), len(player.Tiles)+1)
+		copy(tiles, player.Tiles)
+		if rule.isWinningShape(player, append(tiles, Tile{TileType: tileType})) {
+			waits = append(waits, tileType)
+		}
+	}
+	return waits
+}
+
+func (rule JapaneseBaseRule) Tenpai(player *Player) bool {
+	return len(rule.WaitingTileTypes(player)) > 0
+}
+
+//次の自摸の前に判定する、四風連打・四槓散了・四家立直・荒牌平局
+func (rule JapaneseBaseRule) CanRyuukyoku() (DrawType, error) {
+	maj := rule.Maj
+	if maj.Players.Now().Phase.Check(AddTile) != nil {
+		return 0, errors.New("Not before drawing. ")
+	}
+	options := rule.options()
+	if options.SuufonRenda && rule.fourWinds() {
+		return DrawFourWinds, nil
+	}
+	if options.Suukaikan && rule.fourKans() {
+		return DrawFourKans, nil
+	}
+	if options.SuuchaRiichi && rule.fourRiichi() {
+		return DrawFourRiichi, nil
+	}
+	if maj.RemainderTilesCanDraw() == 0 {
+		return DrawExhaustive, nil
+	}
+	return 0, errors.New("Can not ryuukyoku. ")
+}
+
+func (rule JapaneseBaseRule) fourWinds() bool {
+	if rule.sanma() {
+		return false
+	}
+	var wind TileType
+	ok := true
+	rule.Maj.Players.Do(
+		func(player *Player) {
+			if len(player.Discards) != 1 || rule.Maj.HasCalled() {
+				ok = false
+				return
+			}
+			tileType := player.Discards[0].TileType
+			if !tileType.IsWind() || (wind != None && wind != tileType) {
+				ok = false
+			}
+			wind = tileType
+		},
+	)
+	return ok
+}
+
+//一人で四槓子を狙う場合は続行
+func (rule JapaneseBaseRule) fourKans() bool {
+	kans := 0
+	players := 0
+	rule.Maj.Players.Do(
+		func(player *Player) {
+			if len(player.XXXXs) > 0 {
+				kans += len(player.XXXXs)
+				players++
+			}
+		},
+	)
+	return kans >= 4 && players > 1
+}
+
+func (rule JapaneseBaseRule) fourRiichi() bool {
+	riichi := 0
+	rule.Maj.Players.Do(
+		func(player *Player) {
+			if !player.Riichi.First() {
+				riichi++
+			}
+		},
+	)
+	return riichi == 4
+}
+
+//荒牌平局は不聴罰符
+func (rule JapaneseBaseRule) Ryuukyoku() (*RyuukyokuResult, error) {
+	kind, err := rule.CanRyuukyoku()
+	if err != nil {
+		return nil, err
+	}
+	result := &RyuukyokuResult{Type: kind}
+	if kind != DrawExhaustive {
+		return result, nil
+	}
+	tenpai := 0
+	rule.Maj.Players.Do(
+		func(player *Player) {
+			if rule.Tenpai(player) {
+				result.Tenpai[player.FieldWind] = true
+				tenpai++
+			}
+		},
+	)
+	seats := rule.Maj.Players.Len()
+	if tenpai == 0 || tenpai == seats {
+		return result, nil
+	}
+	rule.Maj.Players.Do(
+		func(player *Player) {
+			if result.Tenpai[player.FieldWind] {
+				result.Deltas[player.FieldWind] = NotenPenalty / tenpai
+			} else {
+				result.Deltas[player.FieldWind] = -NotenPenalty / (seats - tenpai)
+			}
+			player.Score += result.Deltas[player.FieldWind]
+		},
+	)
+	return result, nil
 }
 
 //途中流局
 func (rule JapaneseBaseRule) CanNineYaochus(player *Player) error {
-	if !rule.Maj.Jun().First() {
+	if !rule.options().KyuushuKyuuhai {
+		return errors.New("kyuushu kyuuhai is not allowed")
+	}
+	if player.jun > 1 || rule.Maj.HasCalled() {
 		return errors.New("not first jun")
 	}
 	m := make(map[TileType]bool)
@@ -200,41 +510,118 @@ func (rule JapaneseBaseRule) CanNineYaochus(player *Player) error {
 	return nil
 }
 
-func (rule JapaneseBaseRule) NineYaochus(player *Player) error {
+func (rule JapaneseBaseRule) NineYaochus(player *Player) (*RyuukyokuResult, error) {
 	if err := rule.CanNineYaochus(player); err != nil {
-		return err
+		return nil, err
 	}
-	rule.Maj.Output("九種九牌")
-	return nil
+	return &RyuukyokuResult{Type: DrawNineYaochus}, nil
 }
 
-func (rule JapaneseBaseRule) FuriTen(player *Player) bool {
-	for _, discard := range player.Discards {
-		if _, err := rule.CanAgari(player, discard.Tile); err != nil {
-			return true
+//三家和
+func (rule JapaneseBaseRule) TripleRon(players []*Player) (*RyuukyokuResult, error) {
+	if !rule.options().Sanchahou {
+		return nil, errors.New("sanchahou is not allowed")
+	}
+	if len(players) != 3 {
+		return nil, errors.New("not three players")
+	}
+	for _, player := range players {
+		if _, err := rule.CanRon(player); err != nil {
+			return nil, err
 		}
 	}
+	return &RyuukyokuResult{Type: DrawTripleRon}, nil
+}
+
+//捨て牌に待ち牌がある、または見逃した
+func (rule JapaneseBaseRule) FuriTen(player *Player) bool {
 	if player.Through {
 		return true
 	}
+	for _, tileType := range rule.WaitingTileTypes(player) {
+		for _, discard := range player.Discards {
+			if discard.TileType == tileType {
+				return true
+			}
+		}
+	}
 	return false
 }
 
+//王牌のドラ表示牌の位置、嶺上牌の手前から順に並ぶ
 func (rule JapaneseBaseRule) DoraHints(count uint8, ura bool) []uint8 {
 	indexes := make([]uint8, 0)
-	for i := uint8(0); i < count; i += 2 {
-		indexes = append(indexes, rule.TileAmount()-rule.RyanShanAmount()-i)
+	for i := uint8(0); i < count; i++ {
+		index := rule.TileAmount() - rule.RyanShanAmount() - 1 - i*2
 		if ura {
-			indexes = append(indexes, rule.TileAmount()-rule.RyanShanAmount()-i-1)
+			index--
 		}
+		indexes = append(indexes, index)
 	}
 	return indexes
 }
 
-func (JapaneseBaseRule) PlayersSitDown() Players {
+func (rule JapaneseBaseRule) DoraIndicators() []Tile {
+	return rule.doraIndicators(false)
+}
+
+func (rule JapaneseBaseRule) UraDoraIndicators() []Tile {
+	return rule.doraIndicators(true)
+}
+
+func (rule JapaneseBaseRule) doraIndicators(ura bool) []Tile {
+	if len(rule.Maj.Tiles) != int(rule.TileAmount()) {
+		return nil
+	}
+	indicators := make([]Tile, 0)
+	for _, index := range rule.DoraHints(rule.Maj.Dora(), ura) {
+		indicators = append(indicators, rule.Maj.Tiles[index])
+	}
+	return indicators
+}
+
+//三人麻雀は一萬の次が九萬なので、表示牌を八萬と読み替える
+func (rule JapaneseBaseRule) countDora(tiles []Tile, indicators []Tile) Fan {
+	if !rule.sanma() {
+		return CountDora(tiles, indicators)
+	}
+	read := make([]Tile, len(indicators))
+	for i, indicator := range indicators {
+		if indicator.TileType == Characters1 {
+			indicator.TileType = Characters8
+		}
+		read[i] = indicator
+	}
+	return CountDora(tiles, read)
+}
+
+func CountDora(tiles []Tile, indicators []Tile) Fan {
+	var fan Fan
+	for _, indicator := range indicators {
+		dora := indicator.TileType.Dora()
+		for _, tile := range tiles {
+			if tile.TileType == dora {
+				fan++
+			}
+		}
+	}
+	return fan
+}
+
+func CountAkaDora(tiles []Tile, count int) Fan {
+	var fan Fan
+	for _, tile := range tiles {
+		if tile.IsAka(count) {
+			fan++
+		}
+	}
+	return fan
+}
+
+func (rule JapaneseBaseRule) PlayersSitDown() Players {
 	players := NewPlayers(4)
 	for i := 0; i < 4; i++ {
-		*players.Now() = Player{FieldWind: FieldWind(i), Score: 25000, Tiles: make([]Tile, 0)}
+		*players.Now() = Player{FieldWind: FieldWind(i), Score: rule.StartingPoints(), Tiles: make([]Tile, 0)}
 		players.ToNext()
 	}
 	return players
@@ -251,6 +638,22 @@ func (rule JapaneseBaseRule) CanAgari(player *Player, last Tile) ([]Agari, error
 type Agari struct {
 	YakuTachi []Yaku
 	Fu        int
+	FuItems   []FuItem
+	Shape     HandShape
+	Dora      Fan
+	UraDora   Fan
+	AkaDora   Fan
+	//抜いた北は一枚一飜
+	NukiDora Fan
+}
+
+//ドラは役満に加算しない
+func (agari Agari) Fan(menZen bool) Fan {
+	fan := CountFan(agari.YakuTachi, menZen)
+	if fan >= 役満 {
+		return fan
+	}
+	return fan + agari.Dora + agari.UraDora + agari.AkaDora + agari.NukiDora
 }
 
 func (rule JapaneseBaseRule) Agaris(player *Player, last Tile) []Agari {
@@ -262,25 +665,74 @@ func (rule JapaneseBaseRule) Agaris(player *Player, last Tile) []Agari {
 		tiles = append(tiles, last)
 	}
 	base := rule.Maj.NewWinningHandBase(player, rule.Maj.Players.Now(), SortTiles(tiles))
+	base.Tsumo = last.TileType == None
+	base.LastTile = last
+	if base.Tsumo {
+		base.LastTile = player.LastDraw
+	}
 	if handsBase := base.normalWin(); handsBase != nil {
 		for _, hand := range handsBase {
-			yakuTachi := RealYaku(FindYaku(hand), menZen)
-			if len(yakuTachi) > 0 {
-				agaris = append(agaris, Agari{YakuTachi: yakuTachi, Fu: hand.CountFu(menZen)})
+			if agari, ok := rule.newAgari(hand, menZen); ok {
+				agaris = append(agaris, agari)
 			}
 		}
 	}
 	if hand7 := base.is7PairsWin(); hand7 != nil {
-		yakuTachi := RealYaku(FindYaku(hand7), player.Concealed())
-		agaris = append(agaris, Agari{YakuTachi: yakuTachi, Fu: hand7.CountFu(menZen)})
+		if agari, ok := rule.newAgari(hand7, menZen); ok {
+			agaris = append(agaris, agari)
+		}
 	}
 	if hand13 := base.thirteenOrphansWin(); hand13 != nil {
-		agaris = append(agaris, Agari{[]Yaku{{Name: "国士無双", FanFR: 役無, FanMZ: 役満}}, 0})
+		agaris = append(
+			agaris,
+			Agari{YakuTachi: []Yaku{{Name: "国士無双", FanFR: 役無, FanMZ: 役満}}, Shape: hand13.Shape()},
+		)
 	}
 
+	if len(agaris) == 0 {
+		return nil
+	}
+	all := append(player.AllTiles(), player.Kita...)
+	if last.TileType != None {
+		all = append(all, last)
+	}
+	dora := rule.countDora(all, rule.DoraIndicators())
+	var uraDora Fan
+	if !player.Riichi.First() {
+		uraDora = rule.countDora(all, rule.UraDoraIndicators())
+	}
+	akaDora := CountAkaDora(all, rule.options().AkaDora)
+	for i := range agaris {
+		agaris[i].Dora = dora
+		agaris[i].UraDora = uraDora
+		agaris[i].AkaDora = akaDora
+		agaris[i].NukiDora = Fan(len(player.Kita))
+	}
 	return agaris
 }
 
+func (rule JapaneseBaseRule) newAgari(hand WinningHand, menZen bool) (Agari, bool) {
+	yakuTachi := RealYaku(FindYaku(hand), menZen)
+	//喰い断なし
+	if !menZen && !rule.options().Kuitan {
+		for i, yaku := range yakuTachi {
+			if yaku.Name == "断么九" {
+				yakuTachi = append(yakuTachi[:i], yakuTachi[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(yakuTachi) == 0 {
+		return Agari{}, false
+	}
+	return Agari{
+		YakuTachi: yakuTachi,
+		Fu:        hand.CountFu(menZen),
+		FuItems:   hand.FuItems(menZen),
+		Shape:     hand.Shape(),
+	}, true
+}
+
 type ResultType int8
 
 const (
@@ -289,29 +741,27 @@ const (
 	DrawResult  ResultType = 2
 )
 
-type ResultData struct {
-	Tsumo bool
-	*Player
-	Agari
-}
-
 type Result struct {
 	ResultType
-	data []ResultData
+	Wins      []*WinResult
+	Ryuukyoku *RyuukyokuResult
+
+	//親の連荘
+	Renchan bool
 }
 
-func (result *Result) AddDraw() {
+func (result *Result) AddDraw(ryuukyoku *RyuukyokuResult) {
 	result.ResultType = DrawResult
+	result.Ryuukyoku = ryuukyoku
 }
 
-func (result *Result) AddAgari(tsumo bool, player *Player, agari Agari) {
+func (result *Result) AddAgari(win *WinResult) {
 	result.ResultType = AgariResult
-	resultData := ResultData{tsumo, player, agari}
-	result.data = append(result.data, resultData)
+	result.Wins = append(result.Wins, win)
 }
+
 func (result *Result) Init() {
-	result.ResultType = NoResult
-	result.data = nil
+	*result = Result{}
 }
 
 func (result *Result) Done() bool {
@@ -322,13 +772,70 @@ func (result *Result) Done() bool {
 }
 
 func (rule JapaneseBaseRule) Tiles() []Tile {
-	tiles := make([]Tile, rule.TileAmount())
-	for i := range tiles {
-		tiles[i] = Tile{TileType: TileType(i/4 + 1), Id: int8(i % 4)}
+	tiles := make([]Tile, 0, rule.TileAmount())
+	for i := 0; i < 136; i++ {
+		tile := Tile{TileType: TileType(i/4 + 1), Id: int8(i % 4)}
+		if rule.sanma() && tile.TileType > Characters1 && tile.TileType < Characters9 {
+			continue
+		}
+		tiles = append(tiles, tile)
 	}
 	return tiles
 }
 
+//和了か聴牌で親が連荘する
+func (rule JapaneseBaseRule) Renchan() bool {
+	result := rule.Maj.Result
+	parent := rule.Maj.Players.Parent(rule.Maj.Round)
+	switch result.ResultType {
+	case AgariResult:
+		for _, win := range result.Wins {
+			if win.Winner == parent {
+				return true
+			}
+		}
+	case DrawResult:
+		//途中流局は親の連荘
+		if result.Ryuukyoku.Type != DrawExhaustive {
+			return true
+		}
+		return result.Ryuukyoku.Tenpai[parent.FieldWind]
+	}
+	return false
+}
+
+//トビ、オーラスの和了止め・聴牌止め、西入(南入)とその終了
+func (rule JapaneseBaseRule) IsGameOver(renchan bool) bool {
+	maj := rule.Maj
+	busted := false
+	maj.Players.Do(
+		func(player *Player) {
+			if rule.options().Tobi && player.Score < 0 {
+				busted = true
+			}
+		},
+	)
+	if busted {
+		return true
+	}
+
+	round := maj.Round
+	if !round.IsAllLast() {
+		return false
+	}
+	top := maj.Placements()[0].Player
+	if renchan {
+		parent := maj.Players.Parent(round)
+		return parent == top && parent.Score >= rule.ReturnPoints()
+	}
+	if top.Score >= rule.ReturnPoints() {
+		return true
+	}
+	next := round
+	next.ToNext(renchan, false)
+	return next.FieldWind > round.MaxFieldWind+1
+}
+
 type JapaneseTonPuuRule struct {
 	JapaneseBaseRule
 }
@@ -345,6 +852,37 @@ func (JapaneseHanChanRule) MaxRound() *Round {
 	return NewRound(NanBa, 4)
 }
 
+//三人麻雀の東南戦、北は抜きドラ、吃なし
+type JapaneseSanmaRule struct {
+	JapaneseBaseRule
+}
+
+func (JapaneseSanmaRule) MaxRound() *Round {
+	return NewRound(NanBa, 3)
+}
+
+//未設定ならSanmaRuleOptions
+func (rule *JapaneseSanmaRule) Init(maj *Mahjong) {
+	rule.useSanmaOptions()
+	rule.JapaneseBaseRule.Init(maj)
+}
+
+func (rule *JapaneseSanmaRule) PlayersSitDown() Players {
+	rule.useSanmaOptions()
+	players := NewPlayers(3)
+	for i := 0; i < 3; i++ {
+		*players.Now() = Player{FieldWind: FieldWind(i), Score: rule.StartingPoints(), Tiles: make([]Tile, 0)}
+		players.ToNext()
+	}
+	return players
+}
+
+func (rule *JapaneseSanmaRule) useSanmaOptions() {
+	if rule.Options == nil {
+		rule.Options = &SanmaRuleOptions
+	}
+}
+
 const (
 	TonBa = EastField
 	NanBa = SouthField
@@ -352,7 +890,8 @@ const (
 	PeiBa = NorthField
 )
 
-type Fan int8
+//国標麻将は百番を超えうる
+type Fan int16
 
 const (
 	役無 Fan = 0
@@ -442,6 +981,12 @@ var YakuTachi = []Yaku{
 			},
 		},
 	},
+	{
+		Name:  "オープン立直",
+		FanFR: 役無,
+		FanMZ: 一飜,
+		Check: WinningHand.openReady,
+	},
 	{
 		Name:  "一発",
 		FanFR: 役無,
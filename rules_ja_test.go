@@ -115,7 +115,7 @@ func TestJapaneseBaseRule_Tsumo0(t *testing.T) {
 				rule := JapaneseBaseRule{
 					BaseRule: tt.fields.BaseRule,
 				}
-				if err := rule.Tsumo(tt.args.player); (err != nil) != tt.wantErr {
+				if _, err := rule.Tsumo(tt.args.player); (err != nil) != tt.wantErr {
 					t.Errorf("Tsumo() error = %v, wantErr %v", err, tt.wantErr)
 				}
 			},
@@ -165,7 +165,7 @@ func TestJapaneseBaseRule_Tsumo1(t *testing.T) {
 				rule := JapaneseBaseRule{
 					BaseRule: tt.fields.BaseRule,
 				}
-				if err := rule.Tsumo(tt.args.player); (err != nil) != tt.wantErr {
+				if _, err := rule.Tsumo(tt.args.player); (err != nil) != tt.wantErr {
 					t.Errorf("Tsumo() error = %v, wantErr %v", err, tt.wantErr)
 				}
 			},
@@ -208,7 +208,7 @@ func TestJapaneseBaseRule_Tsumo2(t *testing.T) {
 				rule := JapaneseBaseRule{
 					BaseRule: tt.fields.BaseRule,
 				}
-				if err := rule.Tsumo(tt.args.player); (err != nil) != tt.wantErr {
+				if _, err := rule.Tsumo(tt.args.player); (err != nil) != tt.wantErr {
 					t.Errorf("Tsumo() error = %v, wantErr %v", err, tt.wantErr)
 				}
 			},
@@ -238,6 +238,7 @@ func TestJapaneseBaseRule_CanRiichi(t *testing.T) {
 			fields: fields{rule.BaseRule},
 			args: args{
 				player: &Player{
+					Score: 25000,
 					Tiles: toSampleTiles(
 						[]TileType{
 							Characters5, Characters6, Characters7, Characters7, Characters7,
@@ -330,3 +331,702 @@ func TestJapaneseBaseRule_CanNineYaochus(t *testing.T) {
 		)
 	}
 }
+
+func TestJapaneseBaseRule_Agaris_Dora(t *testing.T) {
+	maj := Init(&JapaneseHanChanRule{})
+	maj.Tiles = maj.Rule.Tiles()
+	rule := JapaneseBaseRule{BaseRule: BaseRule{maj}}
+	dora := rule.DoraHints(1, false)[0]
+	ura := rule.DoraHints(1, true)[0]
+	maj.Tiles[dora] = Tile{TileType: Dots9, Id: 1}
+	maj.Tiles[ura] = Tile{TileType: East, Id: 1}
+	rule.Options = &TenhouRuleOptions
+
+	p0 := maj.Players.Now()
+	p0.Tiles = toSampleTiles(
+		[]TileType{
+			Dots1, Dots1, Dots1, Dots2, Dots3, Dots4, Bamboo5, Bamboo6, Bamboo7, Characters4, Characters5,
+			Characters6, South, South,
+		},
+	)
+	p0.Tiles[0].Id = 1
+	p0.Tiles[1].Id = 2
+	p0.Tiles[2].Id = 3
+	p0.Tiles[6].Id = 1
+
+	agaris := rule.Agaris(p0, Tile{})
+	if len(agaris) == 0 {
+		t.Fatal("Agaris() = nil")
+	}
+	for _, agari := range agaris {
+		if agari.Dora != 3 || agari.UraDora != 0 || agari.AkaDora != 1 {
+			t.Errorf("Agaris() dora = %v/%v/%v, want 3/0/1", agari.Dora, agari.UraDora, agari.AkaDora)
+		}
+	}
+
+	p0.Riichi = 1
+	for _, agari := range rule.Agaris(p0, Tile{}) {
+		if agari.UraDora != 2 {
+			t.Errorf("Agaris() ura dora = %v, want 2", agari.UraDora)
+		}
+	}
+}
+
+func newTestMahjong() (*Mahjong, JapaneseBaseRule) {
+	maj := Init(&JapaneseHanChanRule{})
+	maj.Tiles = maj.Rule.Tiles()
+	rule := JapaneseBaseRule{BaseRule: BaseRule{maj}}
+	maj.Tiles[rule.DoraHints(1, false)[0]] = Tile{TileType: East, Id: 1}
+	maj.Tiles[rule.DoraHints(1, true)[0]] = Tile{TileType: East, Id: 2}
+	return maj, rule
+}
+
+func TestJapaneseBaseRule_Tsumo_WinResult(t *testing.T) {
+	maj, rule := newTestMahjong()
+	p1 := maj.Players.FindField(SouthField)
+	p1.jun = 5
+	p1.Tiles = toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Dots2, Dots3, Dots4, Bamboo6, Bamboo7, Bamboo8, Dots6, Dots7,
+			Dots8, Bamboo4, Bamboo4,
+		},
+	)
+	p1.LastDraw = Tile{TileType: Dots8}
+
+	result, err := rule.Tsumo(p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Tsumo || result.Discarder != nil || result.Winner != p1 {
+		t.Errorf("Tsumo() result = %+v", result)
+	}
+	if result.Fu != 20 || result.Fan != 3 || result.Limit != "" {
+		t.Errorf("Tsumo() = %v符%v飜 %v, want 20符3飜", result.Fu, result.Fan, result.Limit)
+	}
+	if result.Hand.Wait != Ryanmen {
+		t.Errorf("Tsumo() wait = %v, want %v", result.Hand.Wait, Ryanmen)
+	}
+	want := [4]int{-1300, 2700, -700, -700}
+	if result.Deltas != want {
+		t.Errorf("Tsumo() deltas = %v, want %v", result.Deltas, want)
+	}
+	if p1.Score != 27700 {
+		t.Errorf("Score = %v, want 27700", p1.Score)
+	}
+}
+
+func TestJapaneseBaseRule_Ron_WinResult(t *testing.T) {
+	maj, rule := newTestMahjong()
+	p0 := maj.Players.FindField(EastField)
+	p0.jun = 6
+	p0.Riichi = 2
+	p0.Tiles = toSampleTiles(
+		[]TileType{
+			Characters1, Characters2, Characters3, Characters6, Characters7, Characters8, Bamboo7, Bamboo8, Bamboo9,
+			East, East, Dots2, Dots4,
+		},
+	)
+	p3 := maj.Players.FindField(NorthField)
+	maj.LastTile = Tile{TileType: Dots3}
+	maj.LastTilePlayer = p3
+
+	result, err := rule.Ron(p0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tsumo || result.Discarder != p3 || result.WinningTile.TileType != Dots3 {
+		t.Errorf("Ron() result = %+v", result)
+	}
+	if result.Fu != 40 || result.Fan != 1 {
+		t.Errorf("Ron() = %v符%v飜, want 40符1飜", result.Fu, result.Fan)
+	}
+	if SumFu(result.FuItems) != 36 {
+		t.Errorf("Ron() fu items = %v, want 36 in total", result.FuItems)
+	}
+	if len(result.YakuTachi) != 1 || result.YakuTachi[0].Name != "立直" {
+		t.Errorf("Ron() yaku = %v", result.YakuTachi)
+	}
+	if result.Hand.Wait != Kanchan || result.Hand.Head[0].TileType != East {
+		t.Errorf("Ron() hand = %+v", result.Hand)
+	}
+	want := [4]int{2000, 0, 0, -2000}
+	if result.Deltas != want {
+		t.Errorf("Ron() deltas = %v, want %v", result.Deltas, want)
+	}
+}
+
+func TestJapaneseBaseRule_Ron_Deposit(t *testing.T) {
+	maj, rule := newTestMahjong()
+	maj.Round.Honba = 2
+	maj.Deposit = 2000
+	p0 := maj.Players.FindField(EastField)
+	p0.jun = 6
+	p0.Riichi = 2
+	p0.Tiles = toSampleTiles(
+		[]TileType{
+			Characters1, Characters2, Characters3, Characters6, Characters7, Characters8, Bamboo7, Bamboo8, Bamboo9,
+			East, East, Dots2, Dots4,
+		},
+	)
+	p3 := maj.Players.FindField(NorthField)
+	maj.LastTile = Tile{TileType: Dots3}
+	maj.LastTilePlayer = p3
+
+	result, err := rule.Ron(p0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [4]int{4600, 0, 0, -2600}
+	if result.Deltas != want || result.Honba != 600 || result.Deposit != 2000 || maj.Deposit != 0 {
+		t.Errorf("Ron() deltas = %v honba = %v deposit = %v", result.Deltas, result.Honba, result.Deposit)
+	}
+}
+
+func TestJapaneseBaseRule_Tsumo_Deposit(t *testing.T) {
+	maj, rule := newTestMahjong()
+	maj.Round.Honba = 1
+	p1 := maj.Players.FindField(SouthField)
+	p1.jun = 5
+	p1.Tiles = toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Dots2, Dots3, Dots4, Bamboo6, Bamboo7, Bamboo8, Dots6, Dots7,
+			Dots8, Bamboo4, Bamboo4,
+		},
+	)
+	p1.LastDraw = Tile{TileType: Dots8}
+	p2 := maj.Players.FindField(WestField)
+	p2.Riichi = 3
+	p2.Score -= RiichiDeposit
+	maj.Deposit = RiichiDeposit
+
+	result, err := rule.Tsumo(p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [4]int{-1400, 4000, -800, -800}
+	if result.Deltas != want {
+		t.Errorf("Tsumo() deltas = %v, want %v", result.Deltas, want)
+	}
+}
+
+func TestJapaneseBaseRule_Riichi(t *testing.T) {
+	maj, rule := newTestMahjong()
+	p0 := maj.Players.FindField(EastField)
+	p0.jun = 1
+	p0.Phase = RemoveTile
+	p0.Tiles = toSampleTiles(
+		[]TileType{
+			Characters1, Characters2, Characters3, Characters6, Characters7, Characters8, Bamboo7, Bamboo8, Bamboo9,
+			East, East, Dots2, Dots4, North,
+		},
+	)
+	p0.LastDraw = p0.Tiles[13]
+	if err := rule.Riichi(p0, p0.Tiles[13]); err != nil {
+		t.Fatal(err)
+	}
+	if p0.Score != 24000 || maj.Deposit != RiichiDeposit || !p0.Discards[0].Riichi {
+		t.Errorf("Riichi() score = %v deposit = %v", p0.Score, maj.Deposit)
+	}
+
+	//宣言牌での放銃は供託を戻す
+	p1 := maj.Players.FindField(SouthField)
+	p1.Tiles = toSampleTiles(
+		[]TileType{
+			Characters1, Characters2, Characters3, Characters6, Characters7, Characters8, Bamboo7, Bamboo8, Bamboo9,
+			White, White, White, North,
+		},
+	)
+	p1.jun = 1
+	result, err := rule.Ron(p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deposit != 0 || p0.Riichi != 0 || maj.Deposit != 0 {
+		t.Errorf("Ron() on the riichi tile: deposit = %v riichi = %v", result.Deposit, p0.Riichi)
+	}
+}
+
+//一発は次の自分の打牌か誰かの鳴きで消える
+func TestJapaneseBaseRule_Riichi_Ippatsu(t *testing.T) {
+	maj, rule := newTestMahjong()
+	p0 := maj.Players.FindField(EastField)
+	p0.jun = 1
+	p0.Phase = RemoveTile
+	p0.Tiles = toSampleTiles(
+		[]TileType{
+			Characters1, Characters2, Characters3, Characters6, Characters7, Characters8, Bamboo7, Bamboo8, Bamboo9,
+			East, East, Dots2, Dots4, North,
+		},
+	)
+	p0.LastDraw = p0.Tiles[13]
+	if err := rule.Riichi(p0, p0.Tiles[13]); err != nil {
+		t.Fatal(err)
+	}
+	if !p0.ippatsu || p0.Riichi != 1 {
+		t.Fatalf("Riichi() ippatsu = %v riichi = %v", p0.ippatsu, p0.Riichi)
+	}
+	maj.markCalled()
+	if p0.ippatsu {
+		t.Error("ippatsu should be broken by a call")
+	}
+
+	p0.ippatsu = true
+	p0.Tiles = append(p0.Tiles, Tile{TileType: West})
+	maj.dahai(p0, len(p0.Tiles)-1)
+	if p0.ippatsu {
+		t.Error("ippatsu should be broken by the next discard")
+	}
+}
+
+func TestMahjong_Ryuukyoku(t *testing.T) {
+	maj := InitWithSeed(&JapaneseHanChanRule{}, 1)
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := maj.Ryuukyoku(); err == nil {
+		t.Error("Ryuukyoku() before the wall is exhausted should fail")
+	}
+	maj.Players.Now().Phase.Change(Idle)
+	next := maj.Players.ToNext()
+	next.Phase.Change(AddTile)
+	maj.NextTile = uint8(len(maj.Tiles)) - maj.Rule.WallTilesCannotDraw()
+	if _, err := maj.Draw(next); err == nil {
+		t.Error("Draw() from an exhausted wall should fail")
+	}
+
+	noten := toSampleTiles(
+		[]TileType{
+			Characters1, Characters4, Characters7, Dots2, Dots5, Dots8, Bamboo3, Bamboo6, Bamboo9, East, South, West, North,
+		},
+	)
+	tenpai := toSampleTiles(
+		[]TileType{
+			Characters1, Characters2, Characters3, Characters6, Characters7, Characters8, Bamboo7, Bamboo8, Bamboo9,
+			East, East, Dots2, Dots4,
+		},
+	)
+	maj.Players.Do(
+		func(player *Player) {
+			player.Tiles = noten
+		},
+	)
+	maj.Players.FindField(EastField).Tiles = tenpai
+
+	result, err := maj.Ryuukyoku()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [4]int{3000, -1000, -1000, -1000}
+	if result.Deltas != want || !result.Tenpai[EastField] || !result.Renchan {
+		t.Errorf("Ryuukyoku() = %+v", result)
+	}
+	if maj.Restart() != nil || maj.Round.Number != 0 || maj.Round.Honba != 1 {
+		t.Errorf("Round = %+v, want renchan with honba", maj.Round)
+	}
+}
+
+func TestJapaneseBaseRule_CanRyuukyoku_Abortive(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(maj *Mahjong)
+		options RuleOptions
+		want    DrawType
+		wantErr bool
+	}{
+		{
+			"四風連打",
+			func(maj *Mahjong) {
+				maj.Players.Do(
+					func(player *Player) {
+						player.Discards = []DiscardTile{{Tile: Tile{TileType: West}}}
+					},
+				)
+			},
+			DefaultRuleOptions,
+			DrawFourWinds,
+			false,
+		},
+		{
+			"四風連打なし",
+			func(maj *Mahjong) {
+				maj.Players.Do(
+					func(player *Player) {
+						player.Discards = []DiscardTile{{Tile: Tile{TileType: West}}}
+					},
+				)
+			},
+			RuleOptions{},
+			0,
+			true,
+		},
+		{
+			"風牌が違う",
+			func(maj *Mahjong) {
+				maj.Players.Do(
+					func(player *Player) {
+						player.Discards = []DiscardTile{{Tile: Tile{TileType: West}}}
+					},
+				)
+				maj.Players.FindField(NorthField).Discards[0].TileType = North
+			},
+			DefaultRuleOptions,
+			0,
+			true,
+		},
+		{
+			"四槓散了",
+			func(maj *Mahjong) {
+				maj.Players.FindField(EastField).XXXXs = []Quad{{Concealed: true}, {Concealed: true}}
+				maj.Players.FindField(WestField).XXXXs = []Quad{{}, {}}
+			},
+			DefaultRuleOptions,
+			DrawFourKans,
+			false,
+		},
+		{
+			"一人で四槓",
+			func(maj *Mahjong) {
+				maj.Players.FindField(EastField).XXXXs = []Quad{{}, {}, {}, {}}
+			},
+			DefaultRuleOptions,
+			0,
+			true,
+		},
+		{
+			"四家立直",
+			func(maj *Mahjong) {
+				maj.Players.Do(
+					func(player *Player) {
+						player.Riichi = 1
+					},
+				)
+			},
+			DefaultRuleOptions,
+			DrawFourRiichi,
+			false,
+		},
+		{
+			"四家立直なし",
+			func(maj *Mahjong) {
+				maj.Players.Do(
+					func(player *Player) {
+						player.Riichi = 1
+					},
+				)
+			},
+			RuleOptions{SuufonRenda: true, Suukaikan: true},
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj, rule := newTestMahjong()
+				options := tt.options
+				rule.Options = &options
+				maj.Players.Now().Phase.Change(AddTile)
+				tt.setup(maj)
+				got, err := rule.CanRyuukyoku()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("CanRyuukyoku() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("CanRyuukyoku() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMahjong_NineYaochus(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	parent.Tiles = toSampleTiles(
+		[]TileType{
+			Dots1, Dots9, Bamboo1, Bamboo9, Characters1, Characters9, East, South, West, Dots2, Dots3, Dots4, Dots5, Dots6,
+		},
+	)
+	honba := maj.Round.Honba
+	result, err := maj.NineYaochus(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Type != DrawNineYaochus || !result.Renchan || result.Deltas != [4]int{} {
+		t.Errorf("NineYaochus() = %+v", result)
+	}
+	if maj.Restart() != nil || maj.Round.Number != 0 || maj.Round.Honba != honba+1 {
+		t.Errorf("Round = %+v, want renchan with honba", maj.Round)
+	}
+}
+
+func TestMahjong_TripleRon(t *testing.T) {
+	maj, _ := newTestMahjong()
+	discarder := maj.Players.FindField(EastField)
+	discarder.Phase.Change(Idle)
+	maj.LastTile = Tile{TileType: Dots5}
+	maj.LastTilePlayer = discarder
+	//断幺九で三人ともロンできる
+	waiting := toSampleTiles(
+		[]TileType{
+			Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+			Dots2, Dots2, Dots4, Dots6,
+		},
+	)
+	var players []*Player
+	for _, field := range []FieldWind{SouthField, WestField, NorthField} {
+		player := maj.Players.FindField(field)
+		player.Tiles = waiting
+		players = append(players, player)
+	}
+	if _, err := maj.TripleRon(players[:2]...); err == nil {
+		t.Error("TripleRon() with two players should fail")
+	}
+	maj.Rule = &JapaneseHanChanRule{JapaneseBaseRule{BaseRule{maj}, &RuleOptions{}}}
+	if _, err := maj.TripleRon(players...); err == nil {
+		t.Error("TripleRon() should fail when sanchahou is disabled")
+	}
+	maj.Rule = &JapaneseHanChanRule{JapaneseBaseRule{BaseRule{maj}, nil}}
+	result, err := maj.TripleRon(players...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Type != DrawTripleRon || !result.Renchan {
+		t.Errorf("TripleRon() = %+v", result)
+	}
+}
+
+func TestJapaneseBaseRule_FuriTen(t *testing.T) {
+	waiting := []TileType{
+		Characters2, Characters3, Characters4, Characters6, Characters7, Characters8, Bamboo3, Bamboo4, Bamboo5,
+		Dots2, Dots2, Dots4, Dots6,
+	}
+	tests := []struct {
+		name     string
+		discards []TileType
+		through  bool
+		want     bool
+	}{
+		{"待ち牌を捨てていない", []TileType{Dots1, North}, false, false},
+		{"待ち牌を捨てた", []TileType{North, Dots5}, false, true},
+		{"見逃し", nil, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, rule := newTestMahjong()
+				player := &Player{Tiles: toSampleTiles(waiting), Through: tt.through}
+				for _, tileType := range tt.discards {
+					player.Discards = append(player.Discards, DiscardTile{Tile: Tile{TileType: tileType}})
+				}
+				if got := rule.FuriTen(player); got != tt.want {
+					t.Errorf("FuriTen() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func newTestSanma(options *RuleOptions) (*Mahjong, JapaneseBaseRule) {
+	maj := Init(&JapaneseSanmaRule{JapaneseBaseRule{Options: options}})
+	maj.Tiles = maj.Rule.Tiles()
+	rule := maj.Rule.(*JapaneseSanmaRule).JapaneseBaseRule
+	maj.Tiles[rule.DoraHints(1, false)[0]] = Tile{TileType: East, Id: 1}
+	maj.Tiles[rule.DoraHints(1, true)[0]] = Tile{TileType: East, Id: 2}
+	return maj, rule
+}
+
+func TestJapaneseSanmaRule_Start(t *testing.T) {
+	maj := InitWithSeed(&JapaneseSanmaRule{}, 1)
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if maj.Players.Len() != 3 || len(maj.Tiles) != 108 {
+		t.Fatalf("players = %v tiles = %v", maj.Players.Len(), len(maj.Tiles))
+	}
+	for _, tile := range maj.Tiles {
+		if tile.TileType > Characters1 && tile.TileType < Characters9 {
+			t.Fatalf("wall has %v", TilesName[tile.TileType])
+		}
+	}
+	maj.Players.Do(
+		func(player *Player) {
+			want := 13
+			if player.IsParent(maj.Round) {
+				want = 14
+			}
+			if len(player.Tiles) != want || player.Score != 35000 {
+				t.Errorf("player %v has %v tiles and %v points", player.FieldWind, len(player.Tiles), player.Score)
+			}
+		},
+	)
+	if n := maj.RemainderTilesCanDraw(); n != 54 {
+		t.Errorf("RemainderTilesCanDraw() = %v, want 54", n)
+	}
+
+	//親は三人で回る
+	round := maj.Round
+	for _, want := range []FieldWind{EastField, SouthField, WestField, EastField} {
+		if parent := maj.Players.Parent(round); parent.FieldWind != want {
+			t.Errorf("parent of %+v = %v, want %v", round, parent.FieldWind, want)
+		}
+		round.ToNext(false, false)
+	}
+	if round.FieldWind != SouthField || round.Number != 1 {
+		t.Errorf("round = %+v, want south 2", round)
+	}
+}
+
+func TestMahjong_Kita(t *testing.T) {
+	maj := InitWithSeed(&JapaneseSanmaRule{}, 1)
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	parent := maj.Players.Now()
+	north := Tile{TileType: North, Id: 3}
+	parent.Tiles[0] = north
+	remainder := maj.RemainderTilesCanDraw()
+	if options, err := maj.CanKita(parent); err != nil || len(options) == 0 {
+		t.Fatalf("CanKita() = %v, %v", options, err)
+	}
+	if err := maj.Kita(parent, north); err != nil {
+		t.Fatal(err)
+	}
+	if len(parent.Kita) != 1 || len(parent.Tiles) != 13 || parent.Phase != AddTileKan {
+		t.Errorf("after Kita() kita = %v tiles = %v phase = %v", parent.Kita, len(parent.Tiles), parent.Phase.Name())
+	}
+	tile, err := maj.DrawKan(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tile != maj.Tiles[107] || !parent.rinshan || maj.RemainderTilesCanDraw() != remainder-1 {
+		t.Errorf("DrawKan() = %v, remainder = %v", tile, maj.RemainderTilesCanDraw())
+	}
+	if err := maj.Kita(parent, north); err == nil {
+		t.Error("Kita() twice should fail")
+	}
+
+	//吃はない
+	if err := maj.Dahai(parent, parent.LastDraw); err != nil {
+		t.Fatal(err)
+	}
+	maj.closeClaimWindow()
+	next := maj.Players.Now()
+	next.Tiles = toSampleTiles([]TileType{Dots1, Dots2, Dots3, Dots4, Dots5, Dots6, Dots7, Dots8, Dots9, Bamboo1, Bamboo2, Bamboo3, Bamboo4})
+	maj.LastTile = Tile{TileType: Dots3}
+	if _, err := maj.CanChii(next); err == nil {
+		t.Error("CanChii() should fail with three players")
+	}
+
+	four := newTestGame(t)
+	four.Players.Now().Tiles[0] = north
+	if _, err := four.CanKita(four.Players.Now()); err == nil {
+		t.Error("CanKita() should fail with four players")
+	}
+}
+
+func TestJapaneseSanmaRule_Tsumo(t *testing.T) {
+	bisection := SanmaRuleOptions
+	bisection.NorthBisection = true
+	tests := []struct {
+		name    string
+		options *RuleOptions
+		kita    int
+		honba   int8
+		fan     Fan
+		want    [4]int
+	}{
+		{"tsumo loss", nil, 0, 0, 3, [4]int{-1300, 2000, -700, 0}},
+		{"north bisection", &bisection, 0, 0, 3, [4]int{-1700, 2800, -1100, 0}},
+		{"nukidora", nil, 1, 0, 4, [4]int{-2600, 3900, -1300, 0}},
+		{"honba", nil, 0, 2, 3, [4]int{-1500, 2400, -900, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj, rule := newTestSanma(tt.options)
+				maj.Round.Honba = tt.honba
+				p1 := maj.Players.FindField(SouthField)
+				p1.jun = 5
+				p1.Tiles = toSampleTiles(
+					[]TileType{
+						Dots2, Dots3, Dots4, Bamboo2, Bamboo3, Bamboo4, Bamboo6, Bamboo7, Bamboo8, Dots6, Dots7,
+						Dots8, Bamboo4, Bamboo4,
+					},
+				)
+				p1.LastDraw = Tile{TileType: Dots8}
+				for i := 0; i < tt.kita; i++ {
+					p1.Kita = append(p1.Kita, Tile{TileType: North, Id: int8(i)})
+				}
+
+				result, err := rule.Tsumo(p1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Fu != 20 || result.Fan != tt.fan {
+					t.Errorf("Tsumo() = %v符%v飜, want 20符%v飜", result.Fu, result.Fan, tt.fan)
+				}
+				if result.Deltas != tt.want {
+					t.Errorf("Tsumo() deltas = %v, want %v", result.Deltas, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestJapaneseSanmaRule_Dora(t *testing.T) {
+	maj, rule := newTestSanma(nil)
+	tiles := toSampleTiles([]TileType{Characters1, Characters9, North})
+	indicators := toSampleTiles([]TileType{Characters1, West})
+	if fan := rule.countDora(tiles, indicators); fan != 2 {
+		t.Errorf("countDora() = %v, want 2", fan)
+	}
+	if fan := CountDora(tiles, indicators); fan != 1 {
+		t.Errorf("CountDora() = %v, want 1", fan)
+	}
+	if visible := maj.VisibleTiles(maj.Players.Now()); visible[Characters5] != 4 {
+		t.Errorf("VisibleTiles()[5m] = %v, want 4", visible[Characters5])
+	}
+}
+
+func TestJapaneseSanmaRule_Simulate(t *testing.T) {
+	newRule := func() Rule {
+		return &JapaneseSanmaRule{}
+	}
+	stats := Simulate(newRule, []Bot{ShantenBot{}, DefensiveBot{}, TsumogiriBot{}}, []int64{1, 2})
+	if len(stats.Errors) != 0 {
+		t.Fatal(stats.Errors)
+	}
+	if stats.Seats[NorthField] != (SeatStatistics{}) || stats.Hands < 2*6 {
+		t.Errorf("stats = %+v", stats)
+	}
+	maj := InitWithSeed(newRule(), 3)
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	bots := []Bot{ShantenBot{}, ShantenBot{}, ShantenBot{}}
+	for maj.GameOver == nil {
+		if err := maj.PlayBots(bots); err != nil {
+			t.Fatal(err)
+		}
+		if maj.GameOver == nil {
+			if err := maj.Restart(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	total := maj.Deposit
+	for _, placement := range maj.GameOver.Placements {
+		total += placement.Score
+	}
+	if len(maj.GameOver.Placements) != 3 || total != 3*35000 {
+		t.Errorf("placements = %+v, deposit = %v", maj.GameOver.Placements, maj.Deposit)
+	}
+	replayed, err := Replay(newRule(), 3, maj.Events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.GameOver == nil || replayed.GameOver.Placements[0].Score != maj.GameOver.Placements[0].Score {
+		t.Errorf("replayed placements = %+v", replayed.GameOver.Placements)
+	}
+}
@@ -0,0 +1,1434 @@
+package mahjong
+
+import (
+	"errors"
+	"sort"
+)
+
+//国標麻将(中国麻将竞赛规则)、八十一番種で八番縛り、花牌は抜いて補充する
+//立直・ドラ・フリテン・途中流局はなく、親は毎局移る東南西北の四場十六局
+type MCRRule struct {
+	BaseRule
+}
+
+//起和番、花牌は含めない
+const MCRMinimumFan Fan = 8
+
+//基本分、和了者へ全員が払う
+const MCRBasePoints = 8
+
+//名次分
+var MCRRankPoints = [4]float64{4, 2, 1, 0}
+
+type mcrFan int8
+
+//番数の高い順
+const (
+	mcrBigFourWinds mcrFan = iota
+	mcrBigThreeDragons
+	mcrAllGreen
+	mcrNineGates
+	mcrFourKongs
+	mcrSevenShiftedPairs
+	mcrThirteenOrphans
+
+	mcrAllTerminals
+	mcrLittleFourWinds
+	mcrLittleThreeDragons
+	mcrAllHonors
+	mcrFourConcealedPungs
+	mcrPureTerminalChows
+
+	mcrQuadrupleChow
+	mcrFourPureShiftedPungs
+
+	mcrFourPureShiftedChows
+	mcrThreeKongs
+	mcrAllTerminalsAndHonors
+
+	mcrSevenPairs
+	mcrGreaterHonorsAndKnittedTiles
+	mcrAllEvenPungs
+	mcrFullFlush
+	mcrPureTripleChow
+	mcrPureShiftedPungs
+	mcrUpperTiles
+	mcrMiddleTiles
+	mcrLowerTiles
+
+	mcrPureStraight
+	mcrThreeSuitedTerminalChows
+	mcrPureShiftedChows
+	mcrAllFives
+	mcrTriplePung
+	mcrThreeConcealedPungs
+
+	mcrLesserHonorsAndKnittedTiles
+	mcrKnittedStraight
+	mcrUpperFour
+	mcrLowerFour
+	mcrBigThreeWinds
+
+	mcrMixedStraight
+	mcrReversibleTiles
+	mcrMixedTripleChow
+	mcrMixedShiftedPungs
+	mcrChickenHand
+	mcrLastTileDraw
+	mcrLastTileClaim
+	mcrOutWithReplacementTile
+	mcrRobbingTheKong
+
+	mcrAllPungs
+	mcrHalfFlush
+	mcrMixedShiftedChows
+	mcrAllTypes
+	mcrMeldedHand
+	mcrTwoConcealedKongs
+	mcrTwoDragonPungs
+
+	mcrOutsideHand
+	mcrFullyConcealedHand
+	mcrTwoMeldedKongs
+	mcrLastTile
+
+	mcrDragonPung
+	mcrPrevalentWind
+	mcrSeatWind
+	mcrConcealedHand
+	mcrAllChows
+	mcrTileHog
+	mcrDoublePung
+	mcrTwoConcealedPungs
+	mcrConcealedKong
+	mcrAllSimples
+
+	mcrPureDoubleChow
+	mcrMixedDoubleChow
+	mcrShortStraight
+	mcrTwoTerminalChows
+	mcrPungOfTerminalsOrHonors
+	mcrMeldedKong
+	mcrOneVoidedSuit
+	mcrNoHonors
+	mcrEdgeWait
+	mcrClosedWait
+	mcrSingleWait
+	mcrSelfDrawn
+	mcrFlowerTiles
+
+	mcrFanCount
+)
+
+var mcrFans = [mcrFanCount]struct {
+	Name string
+	Fan  Fan
+}{
+	mcrBigFourWinds:      {"大四喜", 88},
+	mcrBigThreeDragons:   {"大三元", 88},
+	mcrAllGreen:          {"绿一色", 88},
+	mcrNineGates:         {"九莲宝灯", 88},
+	mcrFourKongs:         {"四杠", 88},
+	mcrSevenShiftedPairs: {"连七对", 88},
+	mcrThirteenOrphans:   {"十三幺", 88},
+
+	mcrAllTerminals:       {"清幺九", 64},
+	mcrLittleFourWinds:    {"小四喜", 64},
+	mcrLittleThreeDragons: {"小三元", 64},
+	mcrAllHonors:          {"字一色", 64},
+	mcrFourConcealedPungs: {"四暗刻", 64},
+	mcrPureTerminalChows:  {"一色双龙会", 64},
+
+	mcrQuadrupleChow:        {"一色四同顺", 48},
+	mcrFourPureShiftedPungs: {"一色四节高", 48},
+
+	mcrFourPureShiftedChows:  {"一色四步高", 32},
+	mcrThreeKongs:            {"三杠", 32},
+	mcrAllTerminalsAndHonors: {"混幺九", 32},
+
+	mcrSevenPairs:                   {"七对", 24},
+	mcrGreaterHonorsAndKnittedTiles: {"七星不靠", 24},
+	mcrAllEvenPungs:                 {"全双刻", 24},
+	mcrFullFlush:                    {"清一色", 24},
+	mcrPureTripleChow:               {"一色三同顺", 24},
+	mcrPureShiftedPungs:             {"一色三节高", 24},
+	mcrUpperTiles:                   {"全大", 24},
+	mcrMiddleTiles:                  {"全中", 24},
+	mcrLowerTiles:                   {"全小", 24},
+
+	mcrPureStraight:             {"清龙", 16},
+	mcrThreeSuitedTerminalChows: {"三色双龙会", 16},
+	mcrPureShiftedChows:         {"一色三步高", 16},
+	mcrAllFives:                 {"全带五", 16},
+	mcrTriplePung:               {"三同刻", 16},
+	mcrThreeConcealedPungs:      {"三暗刻", 16},
+
+	mcrLesserHonorsAndKnittedTiles: {"全不靠", 12},
+	mcrKnittedStraight:             {"组合龙", 12},
+	mcrUpperFour:                   {"大于五", 12},
+	mcrLowerFour:                   {"小于五", 12},
+	mcrBigThreeWinds:               {"三风刻", 12},
+
+	mcrMixedStraight:          {"花龙", 8},
+	mcrReversibleTiles:        {"推不倒", 8},
+	mcrMixedTripleChow:        {"三色三同顺", 8},
+	mcrMixedShiftedPungs:      {"三色三节高", 8},
+	mcrChickenHand:            {"无番和", 8},
+	mcrLastTileDraw:           {"妙手回春", 8},
+	mcrLastTileClaim:          {"海底捞月", 8},
+	mcrOutWithReplacementTile: {"杠上开花", 8},
+	mcrRobbingTheKong:         {"抢杠和", 8},
+
+	mcrAllPungs:          {"碰碰和", 6},
+	mcrHalfFlush:         {"混一色", 6},
+	mcrMixedShiftedChows: {"三色三步高", 6},
+	mcrAllTypes:          {"五门齐", 6},
+	mcrMeldedHand:        {"全求人", 6},
+	mcrTwoConcealedKongs: {"双暗杠", 6},
+	mcrTwoDragonPungs:    {"双箭刻", 6},
+
+	mcrOutsideHand:        {"全带幺", 4},
+	mcrFullyConcealedHand: {"不求人", 4},
+	mcrTwoMeldedKongs:     {"双明杠", 4},
+	mcrLastTile:           {"和绝张", 4},
+
+	mcrDragonPung:        {"箭刻", 2},
+	mcrPrevalentWind:     {"圈风刻", 2},
+	mcrSeatWind:          {"门风刻", 2},
+	mcrConcealedHand:     {"门前清", 2},
+	mcrAllChows:          {"平和", 2},
+	mcrTileHog:           {"四归一", 2},
+	mcrDoublePung:        {"双同刻", 2},
+	mcrTwoConcealedPungs: {"双暗刻", 2},
+	mcrConcealedKong:     {"暗杠", 2},
+	mcrAllSimples:        {"断幺", 2},
+
+	mcrPureDoubleChow:          {"一般高", 1},
+	mcrMixedDoubleChow:         {"喜相逢", 1},
+	mcrShortStraight:           {"连六", 1},
+	mcrTwoTerminalChows:        {"老少副", 1},
+	mcrPungOfTerminalsOrHonors: {"幺九刻", 1},
+	mcrMeldedKong:              {"明杠", 1},
+	mcrOneVoidedSuit:           {"缺一门", 1},
+	mcrNoHonors:                {"无字", 1},
+	mcrEdgeWait:                {"边张", 1},
+	mcrClosedWait:              {"坎张", 1},
+	mcrSingleWait:              {"单钓将", 1},
+	mcrSelfDrawn:               {"自摸", 1},
+	mcrFlowerTiles:             {"花牌", 1},
+}
+
+//不计、その番種に含まれる番種は数えない
+var mcrExclusions = map[mcrFan][]mcrFan{
+	mcrBigFourWinds:    {mcrPrevalentWind, mcrSeatWind, mcrBigThreeWinds, mcrAllPungs, mcrPungOfTerminalsOrHonors},
+	mcrBigThreeDragons: {mcrDragonPung, mcrTwoDragonPungs},
+	mcrAllGreen:        {mcrHalfFlush},
+	mcrNineGates:       {mcrFullFlush, mcrConcealedHand, mcrNoHonors, mcrPungOfTerminalsOrHonors},
+	mcrFourKongs:       {mcrAllPungs, mcrSingleWait},
+	mcrSevenShiftedPairs: {
+		mcrSevenPairs, mcrFullFlush, mcrFullyConcealedHand, mcrConcealedHand, mcrNoHonors, mcrSingleWait,
+	},
+	mcrThirteenOrphans: {
+		mcrAllTypes, mcrFullyConcealedHand, mcrConcealedHand, mcrSingleWait, mcrAllTerminalsAndHonors,
+	},
+
+	mcrAllTerminals: {
+		mcrAllTerminalsAndHonors, mcrAllPungs, mcrOutsideHand, mcrPungOfTerminalsOrHonors, mcrNoHonors,
+		mcrTriplePung, mcrDoublePung,
+	},
+	mcrLittleFourWinds:    {mcrBigThreeWinds, mcrPungOfTerminalsOrHonors},
+	mcrLittleThreeDragons: {mcrDragonPung, mcrTwoDragonPungs},
+	mcrAllHonors:          {mcrAllPungs, mcrAllTerminalsAndHonors, mcrOutsideHand, mcrPungOfTerminalsOrHonors},
+	mcrFourConcealedPungs: {mcrConcealedHand, mcrAllPungs, mcrThreeConcealedPungs, mcrTwoConcealedPungs},
+	mcrPureTerminalChows: {
+		mcrAllChows, mcrSevenPairs, mcrFullFlush, mcrPureDoubleChow, mcrTwoTerminalChows, mcrNoHonors,
+	},
+
+	mcrQuadrupleChow:        {mcrPureTripleChow, mcrPureShiftedPungs, mcrPureDoubleChow, mcrTileHog},
+	mcrFourPureShiftedPungs: {mcrPureTripleChow, mcrPureShiftedPungs, mcrAllPungs},
+
+	mcrFourPureShiftedChows:  {mcrPureShiftedChows, mcrShortStraight, mcrTwoTerminalChows},
+	mcrThreeKongs:            {mcrTwoMeldedKongs, mcrTwoConcealedKongs, mcrMeldedKong, mcrConcealedKong},
+	mcrAllTerminalsAndHonors: {mcrAllPungs, mcrPungOfTerminalsOrHonors, mcrOutsideHand},
+
+	mcrSevenPairs: {mcrFullyConcealedHand, mcrConcealedHand, mcrSingleWait},
+	mcrGreaterHonorsAndKnittedTiles: {
+		mcrAllTypes, mcrFullyConcealedHand, mcrConcealedHand, mcrSingleWait, mcrLesserHonorsAndKnittedTiles,
+	},
+	mcrAllEvenPungs:     {mcrAllPungs, mcrAllSimples, mcrNoHonors},
+	mcrFullFlush:        {mcrNoHonors},
+	mcrPureTripleChow:   {mcrPureShiftedPungs, mcrPureDoubleChow},
+	mcrPureShiftedPungs: {mcrPureTripleChow},
+	mcrUpperTiles:       {mcrUpperFour, mcrNoHonors},
+	mcrMiddleTiles:      {mcrAllSimples, mcrNoHonors},
+	mcrLowerTiles:       {mcrLowerFour, mcrNoHonors},
+
+	mcrPureStraight:             {mcrShortStraight, mcrTwoTerminalChows},
+	mcrThreeSuitedTerminalChows: {mcrMixedDoubleChow, mcrTwoTerminalChows, mcrNoHonors, mcrAllChows},
+	mcrAllFives:                 {mcrAllSimples, mcrNoHonors},
+	mcrTriplePung:               {mcrDoublePung},
+	mcrThreeConcealedPungs:      {mcrTwoConcealedPungs},
+
+	mcrLesserHonorsAndKnittedTiles: {mcrAllTypes, mcrFullyConcealedHand, mcrConcealedHand, mcrSingleWait},
+	mcrUpperFour:                   {mcrNoHonors},
+	mcrLowerFour:                   {mcrNoHonors},
+
+	mcrReversibleTiles:        {mcrOneVoidedSuit},
+	mcrMixedTripleChow:        {mcrMixedDoubleChow},
+	mcrLastTileDraw:           {mcrSelfDrawn},
+	mcrOutWithReplacementTile: {mcrSelfDrawn},
+	mcrRobbingTheKong:         {mcrLastTile},
+
+	mcrMeldedHand:        {mcrSingleWait},
+	mcrTwoConcealedKongs: {mcrConcealedKong, mcrTwoConcealedPungs},
+	mcrTwoDragonPungs:    {mcrDragonPung},
+
+	mcrFullyConcealedHand: {mcrConcealedHand, mcrSelfDrawn},
+	mcrTwoMeldedKongs:     {mcrMeldedKong},
+
+	mcrAllChows:   {mcrNoHonors},
+	mcrAllSimples: {mcrNoHonors},
+}
+
+func (fan mcrFan) excludes(other mcrFan) bool {
+	for _, excluded := range mcrExclusions[fan] {
+		if excluded == other {
+			return true
+		}
+	}
+	return false
+}
+
+//番種ごとの数
+type mcrCounts [mcrFanCount]int
+
+//高い番種から順に、含まれる番種を消す
+func (counts *mcrCounts) exclude() {
+	for fan := mcrFan(0); fan < mcrFanCount; fan++ {
+		if counts[fan] == 0 {
+			continue
+		}
+		for _, excluded := range mcrExclusions[fan] {
+			counts[excluded] = 0
+		}
+	}
+}
+
+//起和は花牌を除いて数える
+func (counts *mcrCounts) fan(flowers bool) Fan {
+	var fan Fan
+	for i, n := range counts {
+		if mcrFan(i) == mcrFlowerTiles && !flowers {
+			continue
+		}
+		fan += mcrFans[i].Fan * Fan(n)
+	}
+	return fan
+}
+
+//同じ番種が複数あればまとめる
+func (counts *mcrCounts) yakuTachi() []Yaku {
+	yakuTachi := make([]Yaku, 0)
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		fan := mcrFans[i].Fan * Fan(n)
+		yakuTachi = append(yakuTachi, Yaku{Name: mcrFans[i].Name, FanFR: fan, FanMZ: fan})
+	}
+	return yakuTachi
+}
+
+type mcrSetKind int8
+
+const (
+	mcrChow mcrSetKind = iota
+	mcrPung
+	mcrKong
+	mcrPair
+)
+
+//面子と雀頭、順子は一番小さい牌で表す
+type mcrSet struct {
+	kind mcrSetKind
+	TileType
+	concealed bool
+}
+
+func (set mcrSet) contains(tileType TileType) bool {
+	if set.kind == mcrChow {
+		return set.SameSuit(tileType) && tileType >= set.TileType && tileType <= set.TileType+2
+	}
+	return set.TileType == tileType
+}
+
+func (set mcrSet) isPung() bool {
+	return set.kind == mcrPung || set.kind == mcrKong
+}
+
+func (set mcrSet) outside() bool {
+	if set.kind == mcrChow {
+		return set.Number() == 1 || set.Number() == 7
+	}
+	return set.IsYaochu()
+}
+
+func (set mcrSet) five() bool {
+	if set.kind == mcrChow {
+		return set.Number() >= 3 && set.Number() <= 5
+	}
+	return set.IsSuit() && set.Number() == 5
+}
+
+func mcrSets(hand *WinningHandNormal) []mcrSet {
+	sets := []mcrSet{{mcrPair, hand.Head[0].TileType, true}}
+	for _, triplet := range hand.Triplets {
+		sets = append(sets, mcrSet{mcrPung, triplet.TilesXXX[0].TileType, triplet.Concealed})
+	}
+	for _, sequential := range hand.Sequential {
+		tileTypes := SortTileTypes(sequential.ToTileType())
+		sets = append(sets, mcrSet{mcrChow, tileTypes[0], sequential.Concealed})
+	}
+	for _, quad := range hand.Quad {
+		sets = append(sets, mcrSet{mcrKong, quad.TilesXXXX, quad.Concealed})
+	}
+	return sets
+}
+
+//和了形の分解、特殊形は形そのものが番種になる
+type mcrShape struct {
+	sets    []mcrSet
+	special []mcrFan
+	//组合龙・全不靠の面子にならない牌
+	loose []TileType
+	shape HandShape
+}
+
+//组合龙、筒子・索子・萬子に147・258・369を一つずつ
+var mcrKnittedPatterns = [6][3]int8{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}}
+
+func mcrKnitted(pattern [3]int8) []TileType {
+	tileTypes := make([]TileType, 0, 9)
+	for suit, first := range pattern {
+		for n := first; n <= 9; n += 3 {
+			tileTypes = append(tileTypes, Dots1+TileType(suit*9)+TileType(n-1))
+		}
+	}
+	return tileTypes
+}
+
+//国標で認める全ての和了形
+func (rule MCRRule) shapes(base *WinningHandBase) []mcrShape {
+	shapes := make([]mcrShape, 0)
+	for _, hand := range base.normalWin() {
+		shapes = append(shapes, mcrShape{sets: mcrSets(hand), shape: hand.Shape()})
+	}
+	if hand7 := base.is7PairsWith4SameWin(); hand7 != nil {
+		fan := mcrSevenPairs
+		if mcrShiftedPairs(base.SortedTileTypes) {
+			fan = mcrSevenShiftedPairs
+		}
+		shapes = append(shapes, mcrShape{special: []mcrFan{fan}, shape: hand7.Shape()})
+	}
+	if hand13 := base.thirteenOrphansWin(); hand13 != nil {
+		shapes = append(shapes, mcrShape{special: []mcrFan{mcrThirteenOrphans}, shape: hand13.Shape()})
+	}
+	return append(shapes, rule.knittedShapes(base)...)
+}
+
+//全不靠・七星不靠と、组合龙に一面子一雀頭
+func (rule MCRRule) knittedShapes(base *WinningHandBase) []mcrShape {
+	shapes := make([]mcrShape, 0)
+	tileTypes := base.SortedTileTypes
+	for _, pattern := range mcrKnittedPatterns {
+		knitted := mcrKnitted(pattern)
+		if honors, ok := mcrHonorsAndKnitted(tileTypes, knitted); ok {
+			fan := mcrLesserHonorsAndKnittedTiles
+			if honors == 7 {
+				fan = mcrGreaterHonorsAndKnittedTiles
+			}
+			special := []mcrFan{fan}
+			if honors == 5 {
+				special = append(special, mcrKnittedStraight)
+			}
+			shapes = append(
+				shapes, mcrShape{special: special, loose: tileTypes, shape: HandShape{Tiles: base.SortedHandTiles}},
+			)
+			continue
+		}
+		rest, ok := removeTileTypes(base.SortedHandTiles, knitted)
+		if !ok {
+			continue
+		}
+		sub := rule.Maj.NewWinningHandBase(base.Player, base.Atm, rest)
+		sub.Tsumo = base.Tsumo
+		sub.LastTile = base.LastTile
+		for _, hand := range sub.normalWin() {
+			shape := hand.Shape()
+			shape.Tiles = base.SortedHandTiles
+			shapes = append(
+				shapes,
+				mcrShape{sets: mcrSets(hand), special: []mcrFan{mcrKnittedStraight}, loose: knitted, shape: shape},
+			)
+		}
+	}
+	return shapes
+}
+
+//十四枚が全て違う字牌と组合龙の牌
+func mcrHonorsAndKnitted(tileTypes []TileType, knitted []TileType) (int, bool) {
+	if len(tileTypes) != 14 {
+		return 0, false
+	}
+	honors := 0
+	for i, tileType := range tileTypes {
+		if i > 0 && tileTypes[i-1] == tileType {
+			return 0, false
+		}
+		if tileType.IsHonor() {
+			honors++
+			continue
+		}
+		if !mcrHas(knitted, tileType) {
+			return 0, false
+		}
+	}
+	return honors, true
+}
+
+func mcrHas(tileTypes []TileType, tileType TileType) bool {
+	for _, t := range tileTypes {
+		if t == tileType {
+			return true
+		}
+	}
+	return false
+}
+
+//一枚ずつ抜く、足りなければfalse
+func removeTileTypes(tiles []Tile, tileTypes []TileType) ([]Tile, bool) {
+	rest := append([]Tile(nil), tiles...)
+	for _, tileType := range tileTypes {
+		found := false
+		for i, tile := range rest {
+			if tile.TileType == tileType {
+				rest = append(rest[:i], rest[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return rest, true
+}
+
+//同じ色で続く七つの対子
+func mcrShiftedPairs(tileTypes []TileType) bool {
+	for i := 2; i < len(tileTypes); i += 2 {
+		if !tileTypes[i].SameSuit(tileTypes[0]) || tileTypes[i] != tileTypes[i-2]+1 {
+			return false
+		}
+	}
+	return true
+}
+
+//和了の状況、どの分解でも同じもの
+type mcrWin struct {
+	winTile TileType
+	tsumo   bool
+	//槓子を除いた手牌と副露の枚数
+	counts [Winter + 1]int
+	//槓子を含めて使っている牌
+	present   []TileType
+	prevalent TileType
+	seat      TileType
+	concealed bool
+	melds     int
+	nineGates bool
+	//待ちが一種類
+	single      bool
+	lastTile    bool
+	lastDraw    bool
+	lastClaim   bool
+	replacement bool
+	robbing     bool
+	flowers     int
+}
+
+func (rule MCRRule) newWin(base *WinningHandBase) *mcrWin {
+	maj := rule.Maj
+	player := base.Player
+	win := &mcrWin{
+		winTile:   base.LastTile.TileType,
+		tsumo:     base.Tsumo,
+		prevalent: East + TileType(maj.Round.FieldWind),
+		seat:      East + TileType(player.Wind(maj.Round)),
+		concealed: player.Concealed(),
+		melds:     len(player.XXXs) + len(player.XYZs) + len(player.XXXXs),
+		flowers:   len(player.Flowers),
+	}
+	for _, tileType := range base.SortedTileTypes {
+		win.counts[tileType]++
+	}
+	for _, xxx := range player.XXXs {
+		win.counts[xxx.TilesXXX[0].TileType] += 3
+	}
+	for _, xyz := range player.XYZs {
+		for _, tileType := range xyz.ToTileType() {
+			win.counts[tileType]++
+		}
+	}
+	kongs := make(map[TileType]bool)
+	for _, xxxx := range player.XXXXs {
+		kongs[xxxx.TilesXXXX] = true
+	}
+	for tileType := Dots1; tileType <= Red; tileType++ {
+		if win.counts[tileType] > 0 || kongs[tileType] {
+			win.present = append(win.present, tileType)
+		}
+	}
+	empty := maj.RemainderTilesCanDraw() == 0
+	win.lastDraw = win.tsumo && empty && !player.rinshan
+	win.lastClaim = !win.tsumo && empty && maj.Chankan == nil
+	win.replacement = win.tsumo && player.rinshan
+	win.robbing = !win.tsumo && maj.Chankan != nil
+	win.lastTile = rule.shown(win.winTile, win.tsumo) == 3
+	win.single = len(rule.waits(base)) == 1
+	win.nineGates = win.melds == 0 && isNineGates(base.SortedTileTypes, win.winTile)
+	return win
+}
+
+//卓上に見えている枚数、ロンの和了牌は除く
+func (rule MCRRule) shown(tileType TileType, tsumo bool) int {
+	shown := 0
+	rule.Maj.Players.Do(
+		func(player *Player) {
+			for _, discard := range player.Discards {
+				if !discard.Called && discard.TileType == tileType {
+					shown++
+				}
+			}
+			for _, xxx := range player.XXXs {
+				if xxx.TilesXXX[0].TileType == tileType {
+					shown += 3
+				}
+			}
+			for _, xyz := range player.XYZs {
+				if mcrHas(xyz.ToTileType(), tileType) {
+					shown++
+				}
+			}
+			for _, xxxx := range player.XXXXs {
+				if !xxxx.Concealed && xxxx.TilesXXXX == tileType {
+					shown += 4
+				}
+			}
+		},
+	)
+	if !tsumo {
+		shown--
+	}
+	return shown
+}
+
+//和了牌を除いた手の待ち牌
+func (rule MCRRule) waits(base *WinningHandBase) []TileType {
+	hand, _ := removeTileTypes(base.SortedHandTiles, []TileType{base.LastTile.TileType})
+	counts := countTileTypes(toTileTypes(hand))
+	waits := make([]TileType, 0)
+	for tileType := Dots1; tileType <= Red; tileType++ {
+		//五枚目は待ちにならない
+		if counts[tileType] == 4 {
+			continue
+		}
+		tiles := append(append([]Tile(nil), hand...), Tile{TileType: tileType})
+		sub := rule.Maj.NewWinningHandBase(base.Player, base.Atm, SortTiles(tiles))
+		if len(rule.shapes(sub)) > 0 {
+			waits = append(waits, tileType)
+		}
+	}
+	return waits
+}
+
+//分解ごとの番種
+func (win *mcrWin) count(shape mcrShape) mcrCounts {
+	var counts mcrCounts
+	for _, fan := range shape.special {
+		counts[fan]++
+	}
+	win.countSets(&counts, shape)
+	win.countTiles(&counts)
+	win.countWinning(&counts)
+	counts.exclude()
+	if counts.fan(false) == 0 {
+		counts[mcrChickenHand] = 1
+	}
+	return counts
+}
+
+func (win *mcrWin) countSets(counts *mcrCounts, shape mcrShape) {
+	if len(shape.sets) == 0 {
+		return
+	}
+	pair := None
+	chows := make([]TileType, 0)
+	suitedPungs := make([]TileType, 0)
+	winds, dragons, pungs, concealedPungs, kongs, concealedKongs, exposed := 0, 0, 0, 0, 0, 0, 0
+	outside, fives, even := true, true, true
+	for _, set := range shape.sets {
+		outside = outside && set.outside()
+		fives = fives && set.five()
+		if set.kind != mcrPair && !set.concealed {
+			exposed++
+		}
+		switch {
+		case set.kind == mcrPair:
+			pair = set.TileType
+			even = even && set.IsSuit() && set.Number()%2 == 0
+		case set.kind == mcrChow:
+			chows = append(chows, set.TileType)
+			even = false
+		default:
+			pungs++
+			even = even && set.IsSuit() && set.Number()%2 == 0
+			switch {
+			case set.IsWind():
+				winds++
+			case set.IsDragon():
+				dragons++
+			default:
+				suitedPungs = append(suitedPungs, set.TileType)
+			}
+			if set.kind == mcrKong {
+				kongs++
+				if set.concealed {
+					concealedKongs++
+				}
+			}
+			if set.concealed && !win.claimedPung(set, shape) {
+				concealedPungs++
+			}
+		}
+	}
+	SortTileTypes(chows)
+	SortTileTypes(suitedPungs)
+	mcrCountChows(counts, chows, pair)
+	mcrCountPungs(counts, suitedPungs)
+	win.countHonorPungs(counts, shape.sets, winds, dragons, pair)
+
+	switch kongs {
+	case 4:
+		counts[mcrFourKongs]++
+	case 3:
+		counts[mcrThreeKongs]++
+	case 2:
+		switch concealedKongs {
+		case 2:
+			counts[mcrTwoConcealedKongs]++
+		case 1:
+			counts[mcrConcealedKong]++
+			counts[mcrMeldedKong]++
+		default:
+			counts[mcrTwoMeldedKongs]++
+		}
+	case 1:
+		if concealedKongs == 1 {
+			counts[mcrConcealedKong]++
+		} else {
+			counts[mcrMeldedKong]++
+		}
+	}
+	switch concealedPungs {
+	case 4:
+		counts[mcrFourConcealedPungs]++
+	case 3:
+		counts[mcrThreeConcealedPungs]++
+	case 2:
+		counts[mcrTwoConcealedPungs]++
+	}
+
+	//四面子一雀頭の形
+	if len(shape.sets) == 5 {
+		if pungs == 4 {
+			counts[mcrAllPungs]++
+			if even {
+				counts[mcrAllEvenPungs]++
+			}
+		}
+		if len(chows) == 4 && pair.IsSuit() {
+			counts[mcrAllChows]++
+		}
+		if outside {
+			counts[mcrOutsideHand]++
+		}
+		if fives {
+			counts[mcrAllFives]++
+		}
+		if exposed == 4 && !win.tsumo {
+			counts[mcrMeldedHand]++
+		}
+	}
+	if win.single {
+		win.countWait(counts, shape.sets)
+	}
+}
+
+//ロンした牌でしか作れない刻子は明刻
+func (win *mcrWin) claimedPung(pung mcrSet, shape mcrShape) bool {
+	if win.tsumo || pung.kind != mcrPung || pung.TileType != win.winTile {
+		return false
+	}
+	for _, set := range shape.sets {
+		if set.kind != mcrPung && set.concealed && set.contains(win.winTile) {
+			return false
+		}
+	}
+	for _, tileType := range shape.loose {
+		if tileType == win.winTile {
+			return false
+		}
+	}
+	return true
+}
+
+//待ちが一種類なら辺張・嵌張・単騎のどれか一つ
+func (win *mcrWin) countWait(counts *mcrCounts, sets []mcrSet) {
+	single := false
+	for _, set := range sets {
+		if !set.concealed || !set.contains(win.winTile) {
+			continue
+		}
+		switch set.kind {
+		case mcrChow:
+			index := win.winTile - set.TileType
+			switch {
+			case index == 1:
+				counts[mcrClosedWait]++
+				return
+			case index == 2 && set.Number() == 1, index == 0 && set.Number() == 7:
+				counts[mcrEdgeWait]++
+				return
+			}
+		case mcrPair:
+			single = true
+		}
+	}
+	if single {
+		counts[mcrSingleWait]++
+	}
+}
+
+//風牌・三元牌の刻子と幺九刻
+func (win *mcrWin) countHonorPungs(counts *mcrCounts, sets []mcrSet, winds, dragons int, pair TileType) {
+	switch {
+	case winds == 4:
+		counts[mcrBigFourWinds]++
+	case winds == 3 && pair.IsWind():
+		counts[mcrLittleFourWinds]++
+	case winds == 3:
+		counts[mcrBigThreeWinds]++
+	}
+	switch {
+	case dragons == 3:
+		counts[mcrBigThreeDragons]++
+	case dragons == 2 && pair.IsDragon():
+		counts[mcrLittleThreeDragons]++
+	case dragons == 2:
+		counts[mcrTwoDragonPungs]++
+	case dragons == 1:
+		counts[mcrDragonPung]++
+	}
+	for _, set := range sets {
+		if !set.isPung() {
+			continue
+		}
+		switch {
+		case set.IsTerminals():
+			counts[mcrPungOfTerminalsOrHonors]++
+		case set.IsWind():
+			if set.TileType == win.prevalent {
+				counts[mcrPrevalentWind]++
+			}
+			if set.TileType == win.seat {
+				counts[mcrSeatWind]++
+			}
+			if set.TileType != win.prevalent && set.TileType != win.seat && winds < 3 {
+				counts[mcrPungOfTerminalsOrHonors]++
+			}
+		}
+	}
+}
+
+//手牌全体の色と数字
+func (win *mcrWin) countTiles(counts *mcrCounts) {
+	var suits [3]bool
+	winds, dragons := false, false
+	green, terminals, yaochu, simples, reversible := true, true, true, true, true
+	upper, middle, lower, upperFour, lowerFour := true, true, true, true, true
+	for _, tileType := range win.present {
+		green = green && tileType.IsGreen()
+		terminals = terminals && tileType.IsTerminals()
+		yaochu = yaochu && tileType.IsYaochu()
+		simples = simples && !tileType.IsYaochu()
+		reversible = reversible && mcrReversible[tileType]
+		if !tileType.IsSuit() {
+			winds = winds || tileType.IsWind()
+			dragons = dragons || tileType.IsDragon()
+			continue
+		}
+		suits[tileType.Suit()] = true
+		n := tileType.Number()
+		upper = upper && n >= 7
+		middle = middle && n >= 4 && n <= 6
+		lower = lower && n <= 3
+		upperFour = upperFour && n >= 6
+		lowerFour = lowerFour && n <= 4
+	}
+	nSuits := 0
+	for _, suit := range suits {
+		if suit {
+			nSuits++
+		}
+	}
+	honors := winds || dragons
+	switch {
+	case nSuits == 0:
+		counts[mcrAllHonors]++
+	case nSuits == 1 && !honors:
+		counts[mcrFullFlush]++
+	case nSuits == 1:
+		counts[mcrHalfFlush]++
+	case nSuits == 2:
+		counts[mcrOneVoidedSuit]++
+	}
+	if green {
+		counts[mcrAllGreen]++
+	}
+	if terminals {
+		counts[mcrAllTerminals]++
+	} else if yaochu {
+		counts[mcrAllTerminalsAndHonors]++
+	}
+	if reversible {
+		counts[mcrReversibleTiles]++
+	}
+	if nSuits == 3 && winds && dragons {
+		counts[mcrAllTypes]++
+	}
+	if simples {
+		counts[mcrAllSimples]++
+	}
+	if !honors {
+		counts[mcrNoHonors]++
+		switch {
+		case upper:
+			counts[mcrUpperTiles]++
+		case middle:
+			counts[mcrMiddleTiles]++
+		case lower:
+			counts[mcrLowerTiles]++
+		case upperFour:
+			counts[mcrUpperFour]++
+		case lowerFour:
+			counts[mcrLowerFour]++
+		}
+	}
+	for _, n := range win.counts {
+		if n == 4 {
+			counts[mcrTileHog]++
+		}
+	}
+	if win.nineGates {
+		counts[mcrNineGates]++
+	}
+}
+
+//推不倒の牌
+var mcrReversible = map[TileType]bool{
+	Dots1: true, Dots2: true, Dots3: true, Dots4: true, Dots5: true, Dots8: true, Dots9: true,
+	Bamboo2: true, Bamboo4: true, Bamboo5: true, Bamboo6: true, Bamboo8: true, Bamboo9: true,
+	White: true,
+}
+
+//和了牌を除くと一色の1112345678999
+func isNineGates(tileTypes []TileType, last TileType) bool {
+	if len(tileTypes) != 14 || !tileTypes[0].IsSuit() || tileTypes[0].Number() != 1 || !last.SameSuit(tileTypes[0]) {
+		return false
+	}
+	first := tileTypes[0]
+	var counts [9]int
+	for _, tileType := range tileTypes {
+		if !tileType.SameSuit(first) {
+			return false
+		}
+		counts[tileType-first]++
+	}
+	counts[last-first]--
+	for i, n := range counts {
+		want := 1
+		if i == 0 || i == 8 {
+			want = 3
+		}
+		if n != want {
+			return false
+		}
+	}
+	return true
+}
+
+//和了の仕方と花牌
+func (win *mcrWin) countWinning(counts *mcrCounts) {
+	if win.tsumo {
+		counts[mcrSelfDrawn]++
+	}
+	if win.concealed {
+		if win.tsumo {
+			counts[mcrFullyConcealedHand]++
+		} else {
+			counts[mcrConcealedHand]++
+		}
+	}
+	if win.lastTile {
+		counts[mcrLastTile]++
+	}
+	if win.lastDraw {
+		counts[mcrLastTileDraw]++
+	}
+	if win.lastClaim {
+		counts[mcrLastTileClaim]++
+	}
+	if win.replacement {
+		counts[mcrOutWithReplacementTile]++
+	}
+	if win.robbing {
+		counts[mcrRobbingTheKong]++
+	}
+	counts[mcrFlowerTiles] = win.flowers
+}
+
+//四組の順子の番種
+func mcrFourChows(chows []TileType, pair TileType) (mcrFan, bool) {
+	a, b, c, d := chows[0], chows[1], chows[2], chows[3]
+	if a.SameSuit(d) {
+		step := b - a
+		switch {
+		case a == d:
+			return mcrQuadrupleChow, true
+		case (step == 1 || step == 2) && c-b == step && d-c == step:
+			return mcrFourPureShiftedChows, true
+		case a == b && c == d && a.Number() == 1 && c.Number() == 7 && pair.SameSuit(a) && pair.Number() == 5:
+			return mcrPureTerminalChows, true
+		}
+		return 0, false
+	}
+	if a.SameSuit(b) && c.SameSuit(d) && a.Number() == 1 && b.Number() == 7 && c.Number() == 1 &&
+		d.Number() == 7 && pair.IsSuit() && !pair.SameSuit(a) && !pair.SameSuit(c) && pair.Number() == 5 {
+		return mcrThreeSuitedTerminalChows, true
+	}
+	return 0, false
+}
+
+//三組の順子の番種
+func mcrThreeChows(a, b, c TileType) (mcrFan, bool) {
+	if a.SameSuit(b) && b.SameSuit(c) {
+		switch {
+		case a == c:
+			return mcrPureTripleChow, true
+		case b-a == c-b && (b-a == 1 || b-a == 2):
+			return mcrPureShiftedChows, true
+		case a.Number() == 1 && b.Number() == 4 && c.Number() == 7:
+			return mcrPureStraight, true
+		}
+		return 0, false
+	}
+	if a.Suit() == b.Suit() || b.Suit() == c.Suit() || a.Suit() == c.Suit() {
+		return 0, false
+	}
+	numbers := []int{int(a.Number()), int(b.Number()), int(c.Number())}
+	sort.Ints(numbers)
+	switch {
+	case numbers[0] == 1 && numbers[1] == 4 && numbers[2] == 7:
+		return mcrMixedStraight, true
+	case numbers[0] == numbers[2]:
+		return mcrMixedTripleChow, true
+	case numbers[1] == numbers[0]+1 && numbers[2] == numbers[1]+1:
+		return mcrMixedShiftedChows, true
+	}
+	return 0, false
+}
+
+//二組の順子の番種
+func mcrTwoChows(a, b TileType) (mcrFan, bool) {
+	if !a.SameSuit(b) {
+		if a.Number() == b.Number() {
+			return mcrMixedDoubleChow, true
+		}
+		return 0, false
+	}
+	if a > b {
+		a, b = b, a
+	}
+	switch b - a {
+	case 0:
+		return mcrPureDoubleChow, true
+	case 3:
+		return mcrShortStraight, true
+	case 6:
+		return mcrTwoTerminalChows, true
+	}
+	return 0, false
+}
+
+func mcrCountChows(counts *mcrCounts, chows []TileType, pair TileType) {
+	if len(chows) == 4 {
+		if fan, ok := mcrFourChows(chows, pair); ok {
+			counts[fan]++
+			return
+		}
+	}
+	mcrCountGroups(counts, chows, mcrThreeChows, mcrTwoChows)
+}
+
+//数牌の刻子の番種、槓子を含む
+func mcrCountPungs(counts *mcrCounts, pungs []TileType) {
+	if len(pungs) == 4 && pungs[0].SameSuit(pungs[3]) && pungs[3]-pungs[0] == 3 &&
+		pungs[1] == pungs[0]+1 && pungs[2] == pungs[1]+1 {
+		counts[mcrFourPureShiftedPungs]++
+		return
+	}
+	mcrCountGroups(counts, pungs, mcrThreePungs, mcrTwoPungs)
+}
+
+func mcrThreePungs(a, b, c TileType) (mcrFan, bool) {
+	if a.SameSuit(b) && b.SameSuit(c) {
+		if b == a+1 && c == b+1 {
+			return mcrPureShiftedPungs, true
+		}
+		return 0, false
+	}
+	if a.Suit() == b.Suit() || b.Suit() == c.Suit() || a.Suit() == c.Suit() {
+		return 0, false
+	}
+	numbers := []int{int(a.Number()), int(b.Number()), int(c.Number())}
+	sort.Ints(numbers)
+	switch {
+	case numbers[0] == numbers[2]:
+		return mcrTriplePung, true
+	case numbers[1] == numbers[0]+1 && numbers[2] == numbers[1]+1:
+		return mcrMixedShiftedPungs, true
+	}
+	return 0, false
+}
+
+func mcrTwoPungs(a, b TileType) (mcrFan, bool) {
+	if !a.SameSuit(b) && a.Number() == b.Number() {
+		return mcrDoublePung, true
+	}
+	return 0, false
+}
+
+//三組の番種があればそれと、残りの一組が三組のどれかと作る二組の番種を一つ
+//なければ二組の番種を、一度組んだ面子は他の面子ともう一度だけ組めるように選ぶ
+func mcrCountGroups(
+	counts *mcrCounts, groups []TileType,
+	three func(a, b, c TileType) (mcrFan, bool), two func(a, b TileType) (mcrFan, bool),
+) {
+	n := len(groups)
+	found := false
+	var best, rest mcrFan
+	bestFan, hasRest := Fan(0), false
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			for k := j + 1; k < n; k++ {
+				fan, ok := three(groups[i], groups[j], groups[k])
+				if !ok {
+					continue
+				}
+				total := mcrFans[fan].Fan
+				extra, hasExtra := mcrFan(0), false
+				for l := 0; l < n && !hasExtra; l++ {
+					if l == i || l == j || l == k {
+						continue
+					}
+					for _, m := range []int{i, j, k} {
+						if f, ok := two(groups[l], groups[m]); ok && !fan.excludes(f) {
+							extra, hasExtra = f, true
+							total += mcrFans[f].Fan
+							break
+						}
+					}
+				}
+				if !found || total > bestFan {
+					found, best, bestFan, rest, hasRest = true, fan, total, extra, hasExtra
+				}
+			}
+		}
+	}
+	if found {
+		counts[best]++
+		if hasRest {
+			counts[rest]++
+		}
+		return
+	}
+	type edge struct {
+		i, j int
+		fan  mcrFan
+	}
+	edges := make([]edge, 0)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if fan, ok := two(groups[i], groups[j]); ok {
+				edges = append(edges, edge{i, j, fan})
+			}
+		}
+	}
+	bestMask, bestTotal := 0, Fan(0)
+	for mask := 1; mask < 1<<uint(len(edges)); mask++ {
+		parent := []int{0, 1, 2, 3}
+		var root func(int) int
+		root = func(x int) int {
+			for parent[x] != x {
+				x = parent[x]
+			}
+			return x
+		}
+		total, cycle := Fan(0), false
+		for e, edge := range edges {
+			if mask&(1<<uint(e)) == 0 {
+				continue
+			}
+			a, b := root(edge.i), root(edge.j)
+			if a == b {
+				cycle = true
+				break
+			}
+			parent[a] = b
+			total += mcrFans[edge.fan].Fan
+		}
+		if !cycle && total > bestTotal {
+			bestMask, bestTotal = mask, total
+		}
+	}
+	for e, edge := range edges {
+		if bestMask&(1<<uint(e)) != 0 {
+			counts[edge.fan]++
+		}
+	}
+}
+
+func (rule MCRRule) PlayersSitDown() Players {
+	players := NewPlayers(4)
+	for i := 0; i < 4; i++ {
+		*players.Now() = Player{FieldWind: FieldWind(i), Tiles: make([]Tile, 0)}
+		players.ToNext()
+	}
+	return players
+}
+
+func (MCRRule) Tiles() []Tile {
+	return tilesWithFlowers()
+}
+
+func (MCRRule) MaxRound() *Round {
+	return NewRound(PeiBa, 4)
+}
+
+//王牌はなく、槓と花牌の補充の分だけ山の端から減る
+func (rule MCRRule) WallTilesCannotDraw() uint8 {
+	return uint8(rule.Maj.replacements())
+}
+
+func (MCRRule) CanRiichi(*Player) ([]Tile, error) {
+	return nil, errors.New("MCR has no riichi. ")
+}
+
+func (MCRRule) Riichi(*Player, Tile) error {
+	return errors.New("MCR has no riichi. ")
+}
+
+func (MCRRule) CanOpenRiichi(*Player) ([]Tile, error) {
+	return nil, errors.New("MCR has no riichi. ")
+}
+
+func (MCRRule) OpenRiichi(*Player, Tile) error {
+	return errors.New("MCR has no riichi. ")
+}
+
+func (rule MCRRule) CanRon(player *Player) ([]Agari, error) {
+	maj := rule.Maj
+	if err := maj.checkRonTile(player); err != nil {
+		return nil, err
+	}
+	//暗槓は搶槓できない
+	if maj.Chankan != nil && maj.Chankan.Concealed {
+		return nil, errors.New("Can not rob a concealed kong. ")
+	}
+	return rule.CanAgari(player, maj.LastTile)
+}
+
+func (rule MCRRule) Ron(player *Player) (*WinResult, error) {
+	agaris, err := rule.CanRon(player)
+	if err != nil {
+		return nil, err
+	}
+	discarder := rule.Maj.LastTilePlayer
+	result := rule.newWinResult(player, discarder, rule.Maj.LastTile, agaris)
+	rule.pay(result)
+	return result, nil
+}
+
+//一人だけ和了する、放銃者の下家から
+func (rule MCRRule) CanMultiRon(players []*Player) error {
+	if len(players) != 1 {
+		return errors.New("multiple ron is not allowed")
+	}
+	_, err := rule.CanRon(players[0])
+	return err
+}
+
+func (rule MCRRule) CanTsumo(player *Player) ([]Agari, error) {
+	return rule.CanAgari(player, Tile{})
+}
+
+func (rule MCRRule) Tsumo(player *Player) (*WinResult, error) {
+	agaris, err := rule.CanTsumo(player)
+	if err != nil {
+		return nil, err
+	}
+	result := rule.newWinResult(player, nil, player.LastDraw, agaris)
+	rule.pay(result)
+	return result, nil
+}
+
+//全員が基本分を払い、放銃者かツモなら番数も払う
+func (rule MCRRule) pay(result *WinResult) {
+	winner := result.Winner
+	rule.Maj.Players.Do(
+		func(p *Player) {
+			if p == winner {
+				return
+			}
+			s := MCRBasePoints
+			if result.Tsumo || p == result.Discarder {
+				s += int(result.Fan)
+			}
+			result.Deltas[p.FieldWind] -= s
+			result.Deltas[winner.FieldWind] += s
+		},
+	)
+	rule.Maj.Players.Do(
+		func(p *Player) {
+			p.Score += result.Deltas[p.FieldWind]
+		},
+	)
+}
+
+//最も番数の多い分解を選ぶ
+func (rule MCRRule) newWinResult(winner, discarder *Player, tile Tile, agaris []Agari) *WinResult {
+	agari := agaris[0]
+	for _, a := range agaris[1:] {
+		if a.Fan(true) > agari.Fan(true) {
+			agari = a
+		}
+	}
+	result := &WinResult{
+		Winner:      winner,
+		Discarder:   discarder,
+		Tsumo:       discarder == nil,
+		WinningTile: tile,
+		Hand:        agari.Shape,
+		Fan:         agari.Fan(true),
+	}
+	for _, yaku := range agari.YakuTachi {
+		result.YakuTachi = append(result.YakuTachi, YakuFan{yaku.Name, yaku.FanMZ})
+	}
+	return result
+}
+
+func (MCRRule) CanNineYaochus(*Player) error {
+	return errors.New("MCR has no abortive draw. ")
+}
+
+func (MCRRule) NineYaochus(*Player) (*RyuukyokuResult, error) {
+	return nil, errors.New("MCR has no abortive draw. ")
+}
+
+func (MCRRule) TripleRon([]*Player) (*RyuukyokuResult, error) {
+	return nil, errors.New("MCR has no abortive draw. ")
+}
+
+//八番に満たない和了はできない
+func (rule MCRRule) CanAgari(player *Player, last Tile) ([]Agari, error) {
+	if player.handSize(last) != 14 {
+		return nil, errors.New("Player does not have 14 tiles. ")
+	}
+	tiles := append([]Tile(nil), player.Tiles...)
+	if last.TileType != None {
+		tiles = append(tiles, last)
+	}
+	base := rule.Maj.NewWinningHandBase(player, rule.Maj.Players.Now(), SortTiles(tiles))
+	base.Tsumo = last.TileType == None
+	base.LastTile = last
+	if base.Tsumo {
+		base.LastTile = player.LastDraw
+	}
+	shapes := rule.shapes(base)
+	if len(shapes) == 0 {
+		return nil, errors.New("Player cannot agari. ")
+	}
+	win := rule.newWin(base)
+	agaris := make([]Agari, 0)
+	for _, shape := range shapes {
+		counts := win.count(shape)
+		if counts.fan(false) < MCRMinimumFan {
+			continue
+		}
+		agaris = append(agaris, Agari{YakuTachi: counts.yakuTachi(), Shape: shape.shape})
+	}
+	if len(agaris) == 0 {
+		return nil, errors.New("Less than 8 fan. ")
+	}
+	return agaris, nil
+}
+
+func (MCRRule) DoraIndicators() []Tile {
+	return nil
+}
+
+//親は和了に関わらず下家へ
+func (MCRRule) Renchan() bool {
+	return false
+}
+
+func (rule MCRRule) IsGameOver(bool) bool {
+	return rule.Maj.Round.IsAllLast()
+}
+
+func (MCRRule) Settle(placements []Placement) {
+	for i := range placements {
+		placements[i].Point = MCRRankPoints[placements[i].Rank-1]
+	}
+}
+
+//荒庄は点数の移動なし
+func (rule MCRRule) CanRyuukyoku() (DrawType, error) {
+	maj := rule.Maj
+	if maj.Players.Now().Phase.Check(AddTile) != nil {
+		return 0, errors.New("Not before drawing. ")
+	}
+	if maj.RemainderTilesCanDraw() == 0 {
+		return DrawExhaustive, nil
+	}
+	return 0, errors.New("Can not ryuukyoku. ")
+}
+
+func (rule MCRRule) Ryuukyoku() (*RyuukyokuResult, error) {
+	kind, err := rule.CanRyuukyoku()
+	if err != nil {
+		return nil, err
+	}
+	return &RyuukyokuResult{Type: kind}, nil
+}
@@ -0,0 +1,280 @@
+package mahjong
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func newTestMCR() (*Mahjong, MCRRule) {
+	maj := Init(&MCRRule{})
+	maj.Tiles = maj.Rule.Tiles()
+	return maj, *maj.Rule.(*MCRRule)
+}
+
+//一番高い和了と番種名
+func bestMCRAgari(agaris []Agari) (Fan, []string) {
+	best := agaris[0]
+	for _, agari := range agaris[1:] {
+		if agari.Fan(true) > best.Fan(true) {
+			best = agari
+		}
+	}
+	names := make([]string, 0)
+	for _, yaku := range best.YakuTachi {
+		names = append(names, yaku.Name)
+	}
+	sort.Strings(names)
+	return best.Fan(true), names
+}
+
+func TestMCRRule_Fans(t *testing.T) {
+	if mcrFanCount != 81 {
+		t.Errorf("mcrFanCount = %v, want 81", mcrFanCount)
+	}
+	names := make(map[string]bool)
+	for i, fan := range mcrFans {
+		if fan.Name == "" || fan.Fan == 0 || names[fan.Name] {
+			t.Errorf("mcrFans[%v] = %+v", i, fan)
+		}
+		names[fan.Name] = true
+		if i > 0 && fan.Fan > mcrFans[i-1].Fan {
+			t.Errorf("mcrFans[%v] %v is higher than %v", i, fan.Name, mcrFans[i-1].Name)
+		}
+	}
+	for fan, excluded := range mcrExclusions {
+		for _, other := range excluded {
+			if other == fan {
+				t.Errorf("%v excludes itself", mcrFans[fan].Name)
+			}
+		}
+	}
+}
+
+func TestMCRRule_CanAgari(t *testing.T) {
+	tests := []struct {
+		name    string
+		hand    string
+		last    string
+		tsumo   bool
+		flowers int
+		fan     Fan
+		want    []string
+	}{
+		{"chicken hand", "45s77z c234m c678p p888s", "6s", false, 0, 8, []string{"无番和"}},
+		{"flowers", "45s77z c234m c678p p888s", "6s", false, 2, 10, []string{"无番和", "花牌"}},
+		{
+			"pure straight", "12346789p234s88m", "5p", true, 0, 23,
+			[]string{"不求人", "坎张", "平和", "清龙"},
+		},
+		{
+			"all pungs", "3336669m p111z p222z", "9m", false, 0, 19,
+			[]string{"单钓将", "双暗刻", "圈风刻", "混一色", "碰碰和", "门风刻"},
+		},
+		{
+			"mixed triple chow", "123789m123p123s5s", "5s", false, 0, 14,
+			[]string{"三色三同顺", "单钓将", "平和", "老少副", "门前清"},
+		},
+		//一色三同顺より刻子に分けた方が高い
+		{
+			"pure shifted pungs", "2223334445678p", "8p", true, 0, 70,
+			[]string{"一色三节高", "三暗刻", "不求人", "断幺", "清一色"},
+		},
+		{"seven pairs", "1199m2288p55s773z", "3z", true, 0, 31, []string{"七对", "五门齐", "自摸"}},
+		{"thirteen orphans", "19m19p19s1234567z", "1m", false, 0, 88, []string{"十三幺"}},
+		{
+			"knitted straight", "147m258p369s1234z", "5z", true, 0, 25,
+			[]string{"全不靠", "组合龙", "自摸"},
+		},
+		{"nine gates", "1112345678999m", "5m", false, 0, 90, []string{"九莲宝灯", "双暗刻"}},
+		{"big four winds", "44z55m p111z p222z p333z", "4z", true, 0, 95, []string{"大四喜", "混一色", "自摸"}},
+		//以下は《中国麻将竞赛规则》(国家体育总局、1998)の番種表の不计と計分原則の套算一次原则による
+		//大三元は箭刻・双箭刻を不计
+		{
+			"big three dragons", "123m9p p555z p666z p777z", "9p", false, 0, 94,
+			[]string{"全带幺", "单钓将", "大三元", "缺一门"},
+		},
+		//清龙は连六・老少副を不计、暗杠の槓子も三枚と数える
+		{
+			"pure straight with kong", "123m456m789m2p a1111p", "2p", false, 0, 24,
+			[]string{"单钓将", "幺九刻", "无字", "暗杠", "清龙", "缺一门", "门前清"},
+		},
+		//三色三节高の三組のどれかともう一度だけ組める双同刻
+		{
+			"mixed shifted pungs", "444m5z p222m p333p p444s", "5z", false, 0, 17,
+			[]string{"三色三节高", "单钓将", "双同刻", "碰碰和"},
+		},
+		//四組の順子は一度組んだ順子ともう一度だけ組め、喜相逢二つと连六一つ、平和は无字を不计
+		{
+			"two chow pairs", "123m456m123p456p5s", "5s", false, 0, 8,
+			[]string{"单钓将", "喜相逢", "平和", "连六", "门前清"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj, rule := newTestMCR()
+				player := maj.Players.FindField(SouthField)
+				hand, err := ParseHand(tt.hand)
+				if err != nil {
+					t.Fatal(err)
+				}
+				player.SetHand(hand)
+				last, err := ParseTile(tt.last)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < tt.flowers; i++ {
+					player.Flowers = append(player.Flowers, Tile{TileType: PlumBlossom + TileType(i)})
+				}
+				if tt.tsumo {
+					player.Tiles = append(player.Tiles, last)
+					player.LastDraw = last
+					last = Tile{}
+				}
+				agaris, err := rule.CanAgari(player, last)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fan, names := bestMCRAgari(agaris)
+				sort.Strings(tt.want)
+				if fan != tt.fan || !reflect.DeepEqual(names, tt.want) {
+					t.Errorf("CanAgari() = %v %v, want %v %v", fan, names, tt.fan, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMCRRule_CanAgari_Minimum(t *testing.T) {
+	maj, rule := newTestMCR()
+	player := maj.Players.FindField(SouthField)
+	hand, _ := ParseHand("234m567m345p67s99s")
+	player.SetHand(hand)
+	//門前清・平和・連六の五番、花牌は起和に数えない
+	player.Flowers = toSampleTiles([]TileType{PlumBlossom, Orchid, Chrysanthemum})
+	if agaris, err := rule.CanAgari(player, Tile{TileType: Bamboo8}); err == nil {
+		t.Errorf("CanAgari() = %+v, want error", agaris)
+	}
+}
+
+//槓子を三枚と数えて十四枚でなければ和了にならない
+func TestMCRRule_CanAgari_TileCount(t *testing.T) {
+	for _, notation := range []string{"123456789m1p", "123456789m11p1z", "123m456m789m23p a9999s"} {
+		maj, rule := newTestMCR()
+		player := maj.Players.FindField(SouthField)
+		hand, err := ParseHand(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		player.SetHand(hand)
+		if agaris, err := rule.CanAgari(player, Tile{TileType: Dots1}); err == nil {
+			t.Errorf("CanAgari(%v) = %+v, want error", notation, agaris)
+		}
+	}
+}
+
+func TestMCRRule_Ron(t *testing.T) {
+	maj, rule := newTestMCR()
+	winner := maj.Players.FindField(SouthField)
+	hand, _ := ParseHand("3336669m p111z p222z")
+	winner.SetHand(hand)
+	discarder := maj.Players.FindField(NorthField)
+	maj.LastTile = Tile{TileType: Characters9, Id: 3}
+	maj.LastTilePlayer = discarder
+	maj.Players.Now().Phase = AddTile
+
+	result, err := rule.Ron(winner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Fan != 19 || result.Discarder != discarder || result.Tsumo {
+		t.Errorf("Ron() result = %+v", result)
+	}
+	want := [4]int{-8, 43, -8, -27}
+	if result.Deltas != want || winner.Score != 43 {
+		t.Errorf("Ron() deltas = %v, want %v", result.Deltas, want)
+	}
+
+	//次の人がツモった後はロンできない
+	maj.Players.Now().Phase = RemoveTile
+	if _, err := rule.CanRon(winner); err == nil {
+		t.Error("CanRon() should fail after the next draw")
+	}
+	if err := rule.CanMultiRon([]*Player{winner, maj.Players.FindField(WestField)}); err == nil {
+		t.Error("CanMultiRon() should fail with two players")
+	}
+}
+
+func TestMCRRule_Tsumo(t *testing.T) {
+	maj, rule := newTestMCR()
+	winner := maj.Players.FindField(EastField)
+	hand, _ := ParseHand("12346789p234s88m")
+	winner.SetHand(hand)
+	winner.Tiles = append(winner.Tiles, Tile{TileType: Dots5, Id: 1})
+	winner.LastDraw = winner.Tiles[len(winner.Tiles)-1]
+
+	result, err := rule.Tsumo(winner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//全員が基本分と番数を払う
+	want := [4]int{93, -31, -31, -31}
+	if result.Fan != 23 || result.Deltas != want {
+		t.Errorf("Tsumo() = %v %v, want 23 %v", result.Fan, result.Deltas, want)
+	}
+}
+
+func TestMCRRule_Start(t *testing.T) {
+	maj := InitWithSeed(&MCRRule{}, 1)
+	if err := maj.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if len(maj.Tiles) != 144 || maj.Rule.DoraIndicators() != nil {
+		t.Fatalf("tiles = %v indicators = %v", len(maj.Tiles), maj.Rule.DoraIndicators())
+	}
+	flowers := 0
+	maj.Players.Do(
+		func(player *Player) {
+			for _, tile := range player.Tiles {
+				if tile.IsFlower() {
+					t.Errorf("player %v has %v in hand", player.FieldWind, TilesName[tile.TileType])
+				}
+			}
+			flowers += len(player.Flowers)
+		},
+	)
+	if flowers != maj.FlowerCount() {
+		t.Errorf("FlowerCount() = %v, want %v", maj.FlowerCount(), flowers)
+	}
+	if n := int(maj.RemainderTilesCanDraw()); n != 144-53-flowers {
+		t.Errorf("RemainderTilesCanDraw() = %v, want %v", n, 144-53-flowers)
+	}
+	if _, err := maj.Rule.CanRiichi(maj.Players.Now()); err == nil {
+		t.Error("CanRiichi() should fail")
+	}
+}
+
+func TestMCRRule_Settle(t *testing.T) {
+	_, rule := newTestMCR()
+	placements := []Placement{{Rank: 1, Score: 40}, {Rank: 2, Score: 8}, {Rank: 3, Score: -8}, {Rank: 4, Score: -40}}
+	rule.Settle(placements)
+	for i, placement := range placements {
+		if placement.Point != MCRRankPoints[i] {
+			t.Errorf("placement %v point = %v, want %v", i, placement.Point, MCRRankPoints[i])
+		}
+	}
+}
+
+func TestMCRRule_Simulate(t *testing.T) {
+	newRule := func() Rule {
+		return &MCRRule{}
+	}
+	stats := Simulate(newRule, []Bot{ShantenBot{}, DefensiveBot{}, TsumogiriBot{}, ShantenBot{}}, []int64{1})
+	if len(stats.Errors) != 0 {
+		t.Fatal(stats.Errors)
+	}
+	if stats.Hands != 16 {
+		t.Errorf("hands = %v, want 16", stats.Hands)
+	}
+}
@@ -29,6 +29,7 @@ func Tenho() {
 		seed := time.Now().UnixNano() + i
 		fmt.Println("seed:", seed)
 		maj = mahjong.InitWithSeed(&mahjong.JapaneseHanChanRule{}, seed)
+		maj.Listener = mahjong.TextListener{Writer: os.Stdout}
 		players = &maj.Players
 		self = players.Now()
 		err := maj.Start()
@@ -49,6 +50,7 @@ func Game() {
 	seed := time.Now().UnixNano()
 	fmt.Println("seed:", seed)
 	maj = mahjong.InitWithSeed(&mahjong.JapaneseHanChanRule{}, seed)
+	maj.Listener = mahjong.TextListener{Writer: os.Stdout}
 	players = &maj.Players
 	self = players.Now()
 
@@ -81,32 +83,114 @@ func PrintPlayerStatus(player *mahjong.Player) {
 		line1 += " <-"
 	}
 	fmt.Println(line1)
-	names := make([]string, 0)
-	for _, tile := range mahjong.SortTiles(player.Tiles) {
-		names = append(names, TileInterface[tile.TileType])
+	fmt.Println(player.Hand(maj.AkaDora()))
+}
+
+func PrintWinResult(result *mahjong.WinResult) {
+	for _, yaku := range result.YakuTachi {
+		fmt.Println(yaku.Name, yaku.Fan, "飜")
+	}
+	line := strconv.Itoa(result.Fu) + "符" + strconv.Itoa(int(result.Fan)) + "飜"
+	if result.Limit != "" {
+		line += " " + result.Limit
+	}
+	fmt.Println(line)
+	for seat, delta := range result.Deltas {
+		if delta != 0 {
+			fmt.Println("プレイヤー"+WindInterface[mahjong.FieldWind(seat)], delta)
+		}
 	}
-	s := strings.Join(names, " ")
-	for _, xyz := range player.XYZs {
-		s += " | " + TileInterface[xyz.TilesXYZ[0].TileType] + " " + TileInterface[xyz.TilesXYZ[1].TileType] + " " + TileInterface[xyz.TilesXYZ[2].TileType]
+}
+
+//鳴き・ロンは宣言し、全員が揃えば結果を出す
+func Declare(player *mahjong.Player, claim mahjong.Claim) error {
+	result, err := maj.Declare(player, claim)
+	if err != nil || result == nil {
+		return err
 	}
-	for _, xxx := range player.XXXs {
-		s += " | " + TileInterface[xxx.TilesXXX[0].TileType] + " " + TileInterface[xxx.TilesXXX[1].TileType] + " " + TileInterface[xxx.TilesXXX[2].TileType]
+	for _, win := range result.Wins {
+		PrintWinResult(win)
 	}
-	for _, xxxx := range player.XXXXs {
-		s += " | " + TileInterface[xxxx.TilesXXXX] + " " + TileInterface[xxxx.TilesXXXX] +
-			" " + TileInterface[xxxx.TilesXXXX] + " " + TileInterface[xxxx.TilesXXXX]
+	if result.Ryuukyoku != nil {
+		fmt.Println(mahjong.DrawNames[result.Ryuukyoku.Type])
 	}
-	fmt.Println(s)
+	return nil
 }
 
-func OpponentsTsumoGiri() {
-	if players.Now() != self {
+//嶺上牌を引く
+func DrawKan(player *mahjong.Player) {
+	if _, err := maj.DrawKan(player); err != nil {
+		fmt.Println(err)
+		return
+	}
+	PrintPlayerStatus(player)
+}
+
+func PrintGameOver(gameOver *mahjong.GameOver) {
+	for _, placement := range gameOver.Placements {
+		fmt.Println(
+			placement.Rank, "プレイヤー"+WindInterface[placement.Player.FieldWind], placement.Score, placement.Point,
+		)
+	}
+}
+
+//自分の番まで他家はボットが打つ
+func Opponents() {
+	bots := make([]mahjong.Bot, 4)
+	players.Do(
+		func(p *mahjong.Player) {
+			if p != self {
+				bots[p.FieldWind] = mahjong.DefensiveBot{}
+			}
+		},
+	)
+	for maj.Result.ResultType == mahjong.NoResult {
+		if window := maj.ClaimWindow; window != nil && window.CanClaim(self, mahjong.ClaimPass) {
+			if _, declared := window.Claims[self.FieldWind]; !declared {
+				return
+			}
+		}
+		if maj.ClaimWindow == nil && players.Now() == self {
+			return
+		}
+		p := players.Now()
+		if err := maj.StepBots(bots); err != nil {
+			fmt.Println(err)
+			return
+		}
+		PrintPlayerStatus(p)
+		players.Do(PrintActions)
+	}
+	for _, win := range maj.Result.Wins {
+		PrintWinResult(win)
+	}
+}
 
+func PrintAdvices(player *mahjong.Player) {
+	advices, err := maj.AdviseDiscards(player, true)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, advice := range advices {
+		var waits []mahjong.TileType
+		for _, tile := range advice.Tiles {
+			waits = append(waits, tile.TileType)
+		}
+		line := TileInterface[advice.TileType] + " 向聴:" + strconv.Itoa(advice.Shanten) +
+			" 受入:" + strconv.Itoa(advice.Total) + " " + TssType(waits)
+		if advice.Value > 0 {
+			line += " 期待値:" + strconv.Itoa(int(advice.Value))
+		}
+		fmt.Println(line)
 	}
 }
 
 func PrintActions(player *mahjong.Player) {
 	actions := maj.PlayerCan(player)
+	if actions.DrawKan {
+		fmt.Println(WindInterface[player.FieldWind], "DrawKan?")
+	}
 	if actions.Chii {
 		fmt.Println(WindInterface[player.FieldWind], "Chii?")
 		fmt.Println(TssXY(actions.ChiiOption))
@@ -131,6 +215,9 @@ func PrintActions(player *mahjong.Player) {
 		fmt.Println(WindInterface[player.FieldWind], "Riichi?")
 		fmt.Println(TssTile(actions.RiichiOption))
 	}
+	if actions.OpenRiichi {
+		fmt.Println(WindInterface[player.FieldWind], "OpenRiichi?")
+	}
 	if actions.Ron {
 		fmt.Println(WindInterface[player.FieldWind], "Ron?")
 	}
@@ -140,6 +227,9 @@ func PrintActions(player *mahjong.Player) {
 	if actions.NineYaochus {
 		fmt.Println(WindInterface[player.FieldWind], "NineYaochus?")
 	}
+	if actions.Ryuukyoku {
+		fmt.Println(WindInterface[player.FieldWind], "Ryuukyoku?")
+	}
 
 }
 
@@ -179,7 +269,7 @@ func Cli() {
 			}
 			err = maj.Dahai(
 				players.FindField(mahjong.FieldWind(p)),
-				firstTile(players.FindField(mahjong.FieldWind(p)), InterfaceTile[cmd[2]]),
+				findTile(players.FindField(mahjong.FieldWind(p)), cmd[2]),
 			)
 			if err != nil {
 				fmt.Println(err)
@@ -188,7 +278,7 @@ func Cli() {
 			PrintPlayerStatus(players.FindField(mahjong.FieldWind(p)))
 			players.Do(PrintActions)
 			continue
-		case "riichi":
+		case "riichi", "openriichi":
 			if count != 3 {
 				break
 			}
@@ -196,9 +286,13 @@ func Cli() {
 			if err != nil {
 				break
 			}
-			err = maj.Riichi(
+			riichi := maj.Riichi
+			if cmd[0] == "openriichi" {
+				riichi = maj.OpenRiichi
+			}
+			err = riichi(
 				players.FindField(mahjong.FieldWind(p)),
-				firstTile(players.FindField(mahjong.FieldWind(p)), InterfaceTile[cmd[2]]),
+				findTile(players.FindField(mahjong.FieldWind(p)), cmd[2]),
 			)
 			if err != nil {
 				fmt.Println(err)
@@ -215,20 +309,22 @@ func Cli() {
 			if err != nil {
 				break
 			}
-			t1 := InterfaceTile[cmd[2]]
+			t1 := ParseTileType(cmd[2])
 			n1, err := strconv.Atoi(cmd[3])
 			if err != nil {
 				break
 			}
-			t2 := InterfaceTile[cmd[4]]
+			t2 := ParseTileType(cmd[4])
 			n2, err := strconv.Atoi(cmd[5])
 			if err != nil {
 				break
 			}
-			err = maj.Chii(
+			err = Declare(
 				players.FindField(mahjong.FieldWind(p)),
-				mahjong.Tile{TileType: t1, Id: int8(n1)},
-				mahjong.Tile{TileType: t2, Id: int8(n2)},
+				mahjong.Claim{
+					ClaimType: mahjong.ClaimChii,
+					Tiles:     []mahjong.Tile{{TileType: t1, Id: int8(n1)}, {TileType: t2, Id: int8(n2)}},
+				},
 			)
 			if err != nil {
 				fmt.Println(err)
@@ -244,20 +340,22 @@ func Cli() {
 			if err != nil {
 				break
 			}
-			t1 := InterfaceTile[cmd[2]]
+			t1 := ParseTileType(cmd[2])
 			n1, err := strconv.Atoi(cmd[3])
 			if err != nil {
 				break
 			}
-			t2 := InterfaceTile[cmd[4]]
+			t2 := ParseTileType(cmd[4])
 			n2, err := strconv.Atoi(cmd[5])
 			if err != nil {
 				break
 			}
-			err = maj.Pon(
+			err = Declare(
 				players.FindField(mahjong.FieldWind(p)),
-				mahjong.Tile{TileType: t1, Id: int8(n1)},
-				mahjong.Tile{TileType: t2, Id: int8(n2)},
+				mahjong.Claim{
+					ClaimType: mahjong.ClaimPon,
+					Tiles:     []mahjong.Tile{{TileType: t1, Id: int8(n1)}, {TileType: t2, Id: int8(n2)}},
+				},
 			)
 			if err != nil {
 				fmt.Println(err)
@@ -273,12 +371,58 @@ func Cli() {
 			if err != nil {
 				break
 			}
-			err = maj.Kan(players.FindField(mahjong.FieldWind(p)))
+			err = Declare(players.FindField(mahjong.FieldWind(p)), mahjong.Claim{ClaimType: mahjong.ClaimKan})
 			if err != nil {
 				fmt.Println(err)
 				continue
 			}
-			PrintPlayerStatus(players.FindField(mahjong.FieldWind(p)))
+			if maj.ClaimWindow == nil {
+				DrawKan(players.FindField(mahjong.FieldWind(p)))
+			}
+			continue
+		case "pass":
+			if count != 2 {
+				break
+			}
+			p, err := strconv.Atoi(cmd[1])
+			if err != nil {
+				break
+			}
+			if err := Declare(players.FindField(mahjong.FieldWind(p)), mahjong.Claim{}); err != nil {
+				fmt.Println(err)
+			}
+			continue
+		case "ankan", "kakan":
+			if count != 3 {
+				break
+			}
+			p, err := strconv.Atoi(cmd[1])
+			if err != nil {
+				break
+			}
+			player := players.FindField(mahjong.FieldWind(p))
+			if cmd[0] == "ankan" {
+				err = maj.AnKan(player, ParseTileType(cmd[2]))
+			} else {
+				err = maj.KaKan(player, findTile(player, cmd[2]))
+			}
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			//搶槓の受付
+			PrintPlayerStatus(player)
+			players.Do(PrintActions)
+			continue
+		case "drawkan":
+			if count != 2 {
+				break
+			}
+			p, err := strconv.Atoi(cmd[1])
+			if err != nil {
+				break
+			}
+			DrawKan(players.FindField(mahjong.FieldWind(p)))
 			continue
 		case "ron":
 			if count != 2 {
@@ -288,7 +432,7 @@ func Cli() {
 			if err != nil {
 				break
 			}
-			err = maj.Ron(players.FindField(mahjong.FieldWind(p)))
+			err = Declare(players.FindField(mahjong.FieldWind(p)), mahjong.Claim{ClaimType: mahjong.ClaimRon})
 			if err != nil {
 				fmt.Println(err)
 				continue
@@ -303,13 +447,78 @@ func Cli() {
 			if err != nil {
 				break
 			}
-			err = maj.Tsumo(players.FindField(mahjong.FieldWind(p)))
+			result, err := maj.Tsumo(players.FindField(mahjong.FieldWind(p)))
 			if err != nil {
 				fmt.Println(err)
 				continue
 			}
+			PrintWinResult(result)
 			PrintPlayerStatus(players.FindField(mahjong.FieldWind(p)))
 			continue
+		case "nineyaochus":
+			if count != 2 {
+				break
+			}
+			p, err := strconv.Atoi(cmd[1])
+			if err != nil {
+				break
+			}
+			result, err := maj.NineYaochus(players.FindField(mahjong.FieldWind(p)))
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			fmt.Println(mahjong.DrawNames[result.Type])
+			continue
+		case "ryuukyoku":
+			if count != 1 {
+				break
+			}
+			result, err := maj.Ryuukyoku()
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			fmt.Println(mahjong.DrawNames[result.Type])
+			for seat, tenpai := range result.Tenpai {
+				fmt.Println("プレイヤー"+WindInterface[mahjong.FieldWind(seat)], tenpai, result.Deltas[seat])
+			}
+			continue
+		case "restart":
+			if count != 1 {
+				break
+			}
+			err := maj.Restart()
+			if err != nil {
+				fmt.Println(err)
+				if maj.GameOver != nil {
+					PrintGameOver(maj.GameOver)
+				}
+				continue
+			}
+			players.Do(
+				func(player *mahjong.Player) {
+					PrintPlayerStatus(player)
+					fmt.Println()
+				},
+			)
+			continue
+		case "hint":
+			if count != 2 {
+				break
+			}
+			p, err := strconv.Atoi(cmd[1])
+			if err != nil {
+				break
+			}
+			PrintAdvices(players.FindField(mahjong.FieldWind(p)))
+			continue
+		case "cpu":
+			if count != 1 {
+				break
+			}
+			Opponents()
+			continue
 		case "show":
 			switch count {
 			case 1:
@@ -416,13 +625,31 @@ func TssXY(tss []mahjong.TilesXY) string {
 	return strings.Join(s, " | ")
 }
 
-func firstTile(player *mahjong.Player, tileType mahjong.TileType) mahjong.Tile {
-	for _, tile := range player.Tiles {
-		if tile.TileType == tileType {
-			return tile
+func ParseTileType(notation string) mahjong.TileType {
+	tile, err := mahjong.ParseTile(notation)
+	if err != nil {
+		return mahjong.None
+	}
+	return tile.TileType
+}
+
+//0mなら赤五、5mなら赤以外を優先する
+func findTile(player *mahjong.Player, notation string) mahjong.Tile {
+	tile, err := mahjong.ParseTile(notation)
+	if err != nil {
+		return mahjong.Tile{}
+	}
+	found := mahjong.Tile{}
+	for _, t := range player.Tiles {
+		if t.TileType != tile.TileType {
+			continue
 		}
+		if t.IsRed() == tile.IsRed() {
+			return t
+		}
+		found = t
 	}
-	return mahjong.Tile{}
+	return found
 }
 
 var WindInterface = map[mahjong.FieldWind]string{
@@ -431,40 +658,3 @@ var WindInterface = map[mahjong.FieldWind]string{
 	mahjong.WestField:  "西",
 	mahjong.NorthField: "北",
 }
-
-var InterfaceTile = map[string]mahjong.TileType{
-	"1p": mahjong.Dots1,
-	"2p": mahjong.Dots2,
-	"3p": mahjong.Dots3,
-	"4p": mahjong.Dots4,
-	"5p": mahjong.Dots5,
-	"6p": mahjong.Dots6,
-	"7p": mahjong.Dots7,
-	"8p": mahjong.Dots8,
-	"9p": mahjong.Dots9,
-	"1s": mahjong.Bamboo1,
-	"2s": mahjong.Bamboo2,
-	"3s": mahjong.Bamboo3,
-	"4s": mahjong.Bamboo4,
-	"5s": mahjong.Bamboo5,
-	"6s": mahjong.Bamboo6,
-	"7s": mahjong.Bamboo7,
-	"8s": mahjong.Bamboo8,
-	"9s": mahjong.Bamboo9,
-	"1m": mahjong.Characters1,
-	"2m": mahjong.Characters2,
-	"3m": mahjong.Characters3,
-	"4m": mahjong.Characters4,
-	"5m": mahjong.Characters5,
-	"6m": mahjong.Characters6,
-	"7m": mahjong.Characters7,
-	"8m": mahjong.Characters8,
-	"9m": mahjong.Characters9,
-	"1z": mahjong.East,
-	"2z": mahjong.South,
-	"3z": mahjong.West,
-	"4z": mahjong.North,
-	"5z": mahjong.White,
-	"6z": mahjong.Green,
-	"7z": mahjong.Red,
-}
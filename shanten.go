@@ -0,0 +1,231 @@
+package mahjong
+
+import "sync"
+
+//鳴いた手は七対子・国士無双にならない
+const ShantenNone = 99
+
+//向聴数、-1は和了形
+type Shanten struct {
+	Normal     int
+	Chiitoitsu int
+	Kokushi    int
+}
+
+func (shanten Shanten) Min() int {
+	min := shanten.Normal
+	if shanten.Chiitoitsu < min {
+		min = shanten.Chiitoitsu
+	}
+	if shanten.Kokushi < min {
+		min = shanten.Kokushi
+	}
+	return min
+}
+
+//melds は副露の数
+func CountShanten(tileTypes []TileType, melds int) Shanten {
+	return Shanten{
+		Normal:     NormalShanten(tileTypes, melds),
+		Chiitoitsu: ChiitoitsuShanten(tileTypes, melds),
+		Kokushi:    KokushiShanten(tileTypes, melds),
+	}
+}
+
+func (player *Player) Melds() int {
+	return len(player.XXXs) + len(player.XYZs) + len(player.XXXXs)
+}
+
+func (player *Player) Shanten() Shanten {
+	return CountShanten(toTileTypes(player.Tiles), player.Melds())
+}
+
+func countTileTypes(tileTypes []TileType) [Red + 1]int {
+	var counts [Red + 1]int
+	for _, tileType := range tileTypes {
+		if tileType >= Dots1 && tileType <= Red {
+			counts[tileType]++
+		}
+	}
+	return counts
+}
+
+//面子・搭子・雀頭の組み合わせ
+type shantenBlocks struct {
+	mentsu int
+	taatsu int
+	head   bool
+}
+
+//色ごとに組み合わせを全探索してから合わせる
+func NormalShanten(tileTypes []TileType, melds int) int {
+	counts := countTileTypes(tileTypes)
+	groups := [][]shantenBlocks{
+		suitBlocks(counts[Dots1:Dots9+1], true),
+		suitBlocks(counts[Bamboo1:Bamboo9+1], true),
+		suitBlocks(counts[Characters1:Characters9+1], true),
+		suitBlocks(counts[East:Red+1], false),
+	}
+	best := 8
+	var combine func(i int, total shantenBlocks)
+	combine = func(i int, total shantenBlocks) {
+		if i == len(groups) {
+			taatsu := total.taatsu
+			if total.mentsu+taatsu > 4 {
+				taatsu = 4 - total.mentsu
+			}
+			shanten := 8 - 2*total.mentsu - taatsu
+			if total.head {
+				shanten--
+			}
+			if shanten < best {
+				best = shanten
+			}
+			return
+		}
+		for _, blocks := range groups[i] {
+			if blocks.head && total.head {
+				continue
+			}
+			combine(
+				i+1, shantenBlocks{total.mentsu + blocks.mentsu, total.taatsu + blocks.taatsu, total.head || blocks.head},
+			)
+		}
+	}
+	combine(0, shantenBlocks{mentsu: melds})
+	return best
+}
+
+//一色の組み合わせは枚数の並びだけで決まるので、並びごとに覚えておく
+var suitBlocksCache = struct {
+	sync.RWMutex
+	blocks map[int][]shantenBlocks
+}{blocks: make(map[int][]shantenBlocks)}
+
+//一色の中で取りうる組み合わせ、suitなら順子と搭子を作れる
+func suitBlocks(counts []int, suit bool) []shantenBlocks {
+	key := 0
+	for _, count := range counts {
+		key = key<<3 | count
+	}
+	key <<= 1
+	if suit {
+		key |= 1
+	}
+	suitBlocksCache.RLock()
+	blocks, ok := suitBlocksCache.blocks[key]
+	suitBlocksCache.RUnlock()
+	if ok {
+		return blocks
+	}
+	blocks = searchSuitBlocks(append([]int(nil), counts...), suit)
+	suitBlocksCache.Lock()
+	suitBlocksCache.blocks[key] = blocks
+	suitBlocksCache.Unlock()
+	return blocks
+}
+
+//全探索して、面子も搭子も多い組み合わせがあるものは除く
+func searchSuitBlocks(counts []int, suit bool) []shantenBlocks {
+	found := make(map[shantenBlocks]bool)
+	var search func(i int, blocks shantenBlocks)
+	search = func(i int, blocks shantenBlocks) {
+		for i < len(counts) && counts[i] == 0 {
+			i++
+		}
+		if i == len(counts) {
+			found[blocks] = true
+			return
+		}
+		if counts[i] >= 3 {
+			counts[i] -= 3
+			search(i, shantenBlocks{blocks.mentsu + 1, blocks.taatsu, blocks.head})
+			counts[i] += 3
+		}
+		if suit && i+2 < len(counts) && counts[i+1] > 0 && counts[i+2] > 0 {
+			counts[i]--
+			counts[i+1]--
+			counts[i+2]--
+			search(i, shantenBlocks{blocks.mentsu + 1, blocks.taatsu, blocks.head})
+			counts[i]++
+			counts[i+1]++
+			counts[i+2]++
+		}
+		if counts[i] >= 2 {
+			counts[i] -= 2
+			if !blocks.head {
+				search(i, shantenBlocks{blocks.mentsu, blocks.taatsu, true})
+			}
+			search(i, shantenBlocks{blocks.mentsu, blocks.taatsu + 1, blocks.head})
+			counts[i] += 2
+		}
+		for gap := 1; suit && gap <= 2; gap++ {
+			if i+gap < len(counts) && counts[i+gap] > 0 {
+				counts[i]--
+				counts[i+gap]--
+				search(i, shantenBlocks{blocks.mentsu, blocks.taatsu + 1, blocks.head})
+				counts[i]++
+				counts[i+gap]++
+			}
+		}
+		//残りは孤立牌
+		count := counts[i]
+		counts[i] = 0
+		search(i+1, blocks)
+		counts[i] = count
+	}
+	search(0, shantenBlocks{})
+	result := make([]shantenBlocks, 0, len(found))
+	for blocks := range found {
+		dominated := false
+		for other := range found {
+			if other != blocks && other.head == blocks.head && other.mentsu >= blocks.mentsu &&
+				other.taatsu >= blocks.taatsu {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			result = append(result, blocks)
+		}
+	}
+	return result
+}
+
+func ChiitoitsuShanten(tileTypes []TileType, melds int) int {
+	if melds != 0 {
+		return ShantenNone
+	}
+	counts := countTileTypes(tileTypes)
+	pairs, kinds := 0, 0
+	for _, count := range counts {
+		if count >= 2 {
+			pairs++
+		}
+		if count >= 1 {
+			kinds++
+		}
+	}
+	shanten := 6 - pairs
+	if kinds < 7 {
+		shanten += 7 - kinds
+	}
+	return shanten
+}
+
+func KokushiShanten(tileTypes []TileType, melds int) int {
+	if melds != 0 {
+		return ShantenNone
+	}
+	counts := countTileTypes(tileTypes)
+	kinds, pair := 0, 0
+	for _, yaochu := range Yaochu {
+		if counts[yaochu] >= 1 {
+			kinds++
+		}
+		if counts[yaochu] >= 2 {
+			pair = 1
+		}
+	}
+	return 13 - kinds - pair
+}
@@ -0,0 +1,135 @@
+package mahjong
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountShanten(t *testing.T) {
+	tests := []struct {
+		name      string
+		tileTypes []TileType
+		melds     int
+		want      Shanten
+	}{
+		{
+			"和了形",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, East, East, East,
+				Red, Red,
+			},
+			0,
+			Shanten{-1, 4, 8},
+		},
+		{
+			"聴牌",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, East, East, East,
+				Red,
+			},
+			0,
+			Shanten{0, 5, 8},
+		},
+		{
+			"二向聴",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Bamboo7, Bamboo8, Bamboo9, East, East, Red, Green,
+				White,
+			},
+			0,
+			Shanten{2, 5, 6},
+		},
+		{
+			"バラバラ",
+			[]TileType{
+				Characters1, Characters4, Characters7, Dots2, Dots5, Dots8, Bamboo3, Bamboo6, Bamboo9, East, South, West,
+				North,
+			},
+			0,
+			Shanten{8, 6, 7},
+		},
+		{
+			"七対子聴牌",
+			[]TileType{
+				Characters1, Characters1, Characters5, Characters5, Dots2, Dots2, Dots8, Dots8, Bamboo3, Bamboo3, East,
+				East, Red,
+			},
+			0,
+			Shanten{3, 0, 9},
+		},
+		{
+			"国士無双十三面",
+			Yaochu,
+			0,
+			Shanten{8, 6, 0},
+		},
+		{
+			"副露あり",
+			[]TileType{Characters1, Characters2, Dots4, Dots5, Dots6, Bamboo7, Bamboo7},
+			2,
+			Shanten{0, ShantenNone, ShantenNone},
+		},
+		{
+			"裸単騎",
+			[]TileType{East},
+			4,
+			Shanten{0, ShantenNone, ShantenNone},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := CountShanten(tt.tileTypes, tt.melds); got != tt.want {
+					t.Errorf("CountShanten() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestPlayer_Shanten(t *testing.T) {
+	player := &Player{
+		Tiles: toSampleTiles([]TileType{Characters1, Characters2, Dots4, Dots5, Dots6, Bamboo7, Bamboo7}),
+		XXXs:  []Triplet{{}},
+		XYZs:  []Sequential{{}},
+	}
+	if got := player.Shanten(); got.Min() != 0 {
+		t.Errorf("Shanten() = %+v, want tenpai", got)
+	}
+}
+
+//覚えた組み合わせは探索し直したものと同じで、渡した枚数は変えない
+func TestSuitBlocks_Cache(t *testing.T) {
+	toSet := func(blocks []shantenBlocks) map[shantenBlocks]bool {
+		set := make(map[shantenBlocks]bool)
+		for _, b := range blocks {
+			set[b] = true
+		}
+		return set
+	}
+	counts := []int{3, 1, 1, 1, 0, 2, 2, 1, 4}
+	want := toSet(searchSuitBlocks(append([]int(nil), counts...), true))
+	for i := 0; i < 2; i++ {
+		if got := toSet(suitBlocks(counts, true)); !reflect.DeepEqual(got, want) {
+			t.Errorf("suitBlocks() = %v, want %v", got, want)
+		}
+	}
+	if !reflect.DeepEqual(counts, []int{3, 1, 1, 1, 0, 2, 2, 1, 4}) {
+		t.Errorf("counts = %v", counts)
+	}
+	//同じ並びでも字牌は順子を作らない
+	run := []int{1, 1, 1, 0, 0, 0, 0}
+	if honors := suitBlocks(run, false); reflect.DeepEqual(toSet(honors), toSet(suitBlocks(run, true))) {
+		t.Errorf("suitBlocks() = %v for honors", honors)
+	}
+}
+
+func BenchmarkNormalShanten(b *testing.B) {
+	tileTypes := []TileType{
+		Characters1, Characters2, Characters3, Characters4, Characters5, Characters6, Characters7, Dots3, Dots4,
+		Dots5, Dots6, Dots7, Bamboo5, Bamboo6,
+	}
+	for i := 0; i < b.N; i++ {
+		NormalShanten(tileTypes, 0)
+	}
+}
@@ -0,0 +1,180 @@
+package mahjong
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+//一半荘でこれを超えたら終わらないものとする
+const simulateMaxHands = 1000
+
+//席(Player.FieldWind)ごとの集計
+type SeatStatistics struct {
+	Wins    int
+	DealIns int
+	//積み棒と供託を除いた和了点の合計
+	Points int
+}
+
+//自己対戦の集計、Hands は局数
+type Statistics struct {
+	Games int
+	Hands int
+	Draws int
+	Seats [4]SeatStatistics
+	//役の名前ごとの和了数
+	Yaku   map[string]int
+	Errors []SimulationError
+}
+
+//止まった対局の種
+type SimulationError struct {
+	Seed int64
+	Err  error
+}
+
+func (err SimulationError) Error() string {
+	return fmt.Sprintf("seed %v: %v", err.Seed, err.Err)
+}
+
+func newStatistics() *Statistics {
+	return &Statistics{Yaku: make(map[string]int)}
+}
+
+func (stats *Statistics) WinRate(seat FieldWind) float64 {
+	return rate(stats.Seats[seat].Wins, stats.Hands)
+}
+
+func (stats *Statistics) DealInRate(seat FieldWind) float64 {
+	return rate(stats.Seats[seat].DealIns, stats.Hands)
+}
+
+func (stats *Statistics) AverageValue(seat FieldWind) float64 {
+	return rate(stats.Seats[seat].Points, stats.Seats[seat].Wins)
+}
+
+func (stats *Statistics) DrawRate() float64 {
+	return rate(stats.Draws, stats.Hands)
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func (stats *Statistics) add(other *Statistics) {
+	stats.Games += other.Games
+	stats.Hands += other.Hands
+	stats.Draws += other.Draws
+	for seat := range stats.Seats {
+		stats.Seats[seat].Wins += other.Seats[seat].Wins
+		stats.Seats[seat].DealIns += other.Seats[seat].DealIns
+		stats.Seats[seat].Points += other.Seats[seat].Points
+	}
+	for name, n := range other.Yaku {
+		stats.Yaku[name] += n
+	}
+	stats.Errors = append(stats.Errors, other.Errors...)
+}
+
+//Listenerで和了と流局を数える
+type statisticsListener struct {
+	NopListener
+	stats *Statistics
+}
+
+func (listener statisticsListener) OnWin(result *WinResult) {
+	seat := &listener.stats.Seats[result.Winner.FieldWind]
+	seat.Wins++
+	seat.Points += result.Deltas[result.Winner.FieldWind] - result.Honba - result.Deposit
+	if result.Discarder != nil {
+		listener.stats.Seats[result.Discarder.FieldWind].DealIns++
+	}
+	for _, yaku := range result.YakuTachi {
+		listener.stats.Yaku[yaku.Name]++
+	}
+}
+
+func (listener statisticsListener) OnRyuukyoku(*RyuukyokuResult) {
+	listener.stats.Draws++
+}
+
+//種ごとに一局ずつ並行して打つ、Mahjongとルールはgoroutineごとに作る
+//ボットは全てのgoroutineで共有するので状態を持たないこと
+func Simulate(newRule func() Rule, bots []Bot, seeds []int64) *Statistics {
+	jobs := make(chan int64)
+	results := make(chan *Statistics)
+	workers := runtime.GOMAXPROCS(0)
+	var wait sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			stats := newStatistics()
+			for seed := range jobs {
+				game, err := SimulateGame(newRule(), bots, seed)
+				stats.add(game)
+				if err != nil {
+					stats.Errors = append(stats.Errors, SimulationError{Seed: seed, Err: err})
+				}
+			}
+			results <- stats
+		}()
+	}
+	go func() {
+		for _, seed := range seeds {
+			jobs <- seed
+		}
+		close(jobs)
+		wait.Wait()
+		close(results)
+	}()
+	total := newStatistics()
+	for stats := range results {
+		total.add(stats)
+	}
+	sort.Slice(
+		total.Errors, func(i, j int) bool {
+			return total.Errors[i].Seed < total.Errors[j].Seed
+		},
+	)
+	return total
+}
+
+//一半荘を終局まで打つ、止まってもそこまでの集計を返す
+//panicしたボットやルールは場所が分かるようにスタックトレースを付けて返す
+func SimulateGame(rule Rule, bots []Bot, seed int64) (stats *Statistics, err error) {
+	stats = newStatistics()
+	stats.Games = 1
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	maj := InitWithSeed(rule, seed)
+	maj.Listener = statisticsListener{stats: stats}
+	if err := maj.Start(); err != nil {
+		return stats, err
+	}
+	for {
+		stats.Hands++
+		if err := maj.PlayBots(bots); err != nil {
+			return stats, err
+		}
+		if maj.GameOver != nil {
+			return stats, nil
+		}
+		if stats.Hands >= simulateMaxHands {
+			return stats, errors.New("Game does not end. ")
+		}
+		if err := maj.Restart(); err != nil {
+			return stats, err
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mahjong
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTonPuuRule() Rule {
+	return &JapaneseTonPuuRule{}
+}
+
+func TestSimulate(t *testing.T) {
+	bots := []Bot{TsumogiriBot{}, ShantenBot{}, DefensiveBot{}, ShantenBot{}}
+	seeds := []int64{1, 2, 3, 4}
+	stats := Simulate(newTonPuuRule, bots, seeds)
+	if len(stats.Errors) != 0 {
+		t.Fatal(stats.Errors)
+	}
+	if stats.Games != len(seeds) || stats.Hands < 4*len(seeds) {
+		t.Errorf("games = %v hands = %v", stats.Games, stats.Hands)
+	}
+	wins, dealIns := 0, 0
+	for seat := range stats.Seats {
+		wins += stats.Seats[seat].Wins
+		dealIns += stats.Seats[seat].DealIns
+		if rate := stats.WinRate(FieldWind(seat)); rate < 0 || rate > 1 {
+			t.Errorf("WinRate(%v) = %v", seat, rate)
+		}
+	}
+	//ダブロンがあるので和了数は局数を超えうる
+	if wins+stats.Draws < stats.Hands || dealIns > wins {
+		t.Errorf("wins = %v deal-ins = %v draws = %v hands = %v", wins, dealIns, stats.Draws, stats.Hands)
+	}
+	if stats.Seats[SouthField].Wins == 0 || stats.AverageValue(SouthField) < 1000 {
+		t.Errorf("shanten bot won %+v", stats.Seats[SouthField])
+	}
+	if stats.Yaku["立直"] == 0 {
+		t.Errorf("yaku = %v", stats.Yaku)
+	}
+
+	//種ごとに再現でき、並行でも逐次でも同じ集計になる
+	sequential := newStatistics()
+	for _, seed := range seeds {
+		game, err := SimulateGame(newTonPuuRule(), bots, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sequential.add(game)
+	}
+	if !reflect.DeepEqual(stats, sequential) {
+		t.Errorf("Simulate() = %+v, sequential = %+v", stats, sequential)
+	}
+}
+
+func TestSimulateGame_Error(t *testing.T) {
+	//南家のボットがいない
+	stats, err := SimulateGame(newTonPuuRule(), []Bot{TsumogiriBot{}}, 1)
+	if err == nil || stats.Games != 1 || stats.Hands != 1 {
+		t.Errorf("SimulateGame() = %+v, %v", stats, err)
+	}
+	all := Simulate(newTonPuuRule, []Bot{TsumogiriBot{}}, []int64{2, 1})
+	if len(all.Errors) != 2 || all.Errors[0].Seed != 1 || all.Errors[1].Seed != 2 {
+		t.Errorf("Errors = %v", all.Errors)
+	}
+}
+
+type panicBot struct{}
+
+func (panicBot) Decide(view *View) Event {
+	panic("panicBot")
+}
+
+func TestSimulateGame_Panic(t *testing.T) {
+	bots := []Bot{panicBot{}, panicBot{}, panicBot{}, panicBot{}}
+	_, err := SimulateGame(newTonPuuRule(), bots, 1)
+	//どのボットで止まったかがスタックトレースに残る
+	if err == nil || !strings.Contains(err.Error(), "panicBot.Decide") {
+		t.Errorf("SimulateGame() = %v, want the stack trace", err)
+	}
+}
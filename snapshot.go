@@ -0,0 +1,301 @@
+package mahjong
+
+import (
+	"bytes"
+	"container/ring"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"math/rand"
+)
+
+//シードと引いた回数を覚えておき、同じ位置まで進めて復元する
+type seedSource struct {
+	seed  int64
+	count uint64
+	src   rand.Source64
+}
+
+func newSeedSource(seed int64, count uint64) *seedSource {
+	source := &seedSource{seed: seed, src: rand.NewSource(seed).(rand.Source64)}
+	for source.count < count {
+		source.Uint64()
+	}
+	return source
+}
+
+func (source *seedSource) Int63() int64 {
+	source.count++
+	return source.src.Int63()
+}
+
+func (source *seedSource) Uint64() uint64 {
+	source.count++
+	return source.src.Uint64()
+}
+
+func (source *seedSource) Seed(seed int64) {
+	source.seed = seed
+	source.count = 0
+	source.src.Seed(seed)
+}
+
+//Playerを指すところは席で持つ、-1は誰もいない
+type seat int8
+
+const noSeat seat = -1
+
+func seatOf(player *Player) seat {
+	if player == nil {
+		return noSeat
+	}
+	return seat(player.FieldWind)
+}
+
+func (maj *Mahjong) playerAt(seat seat) (*Player, error) {
+	if seat == noSeat {
+		return nil, nil
+	}
+	player := maj.Players.FindField(FieldWind(seat))
+	if player == nil {
+		return nil, errors.New("Snapshot refers to an empty seat. ")
+	}
+	return player, nil
+}
+
+type playerSnapshot struct {
+	Player
+	Jun     Jun
+	Rinshan bool
+	Ippatsu bool
+}
+
+type winSnapshot struct {
+	WinResult
+	Winner    seat
+	Discarder seat
+}
+
+type chankanSnapshot struct {
+	Player seat
+	Tile
+	Concealed bool
+}
+
+type claimWindowSnapshot struct {
+	Tile
+	Discarder seat
+	Options   map[FieldWind][]ClaimType
+	Claims    map[FieldWind]Claim
+}
+
+type placementSnapshot struct {
+	Placement
+	Player seat
+}
+
+//ルール以外の対局の状態、席は起家から順
+type snapshot struct {
+	Round          Round
+	Seed           int64
+	SeedCount      uint64
+	Players        []playerSnapshot
+	Now            FieldWind
+	ResultType     ResultType
+	Wins           []winSnapshot
+	Ryuukyoku      *RyuukyokuResult
+	Renchan        bool
+	NextTile       uint8
+	Tiles          []Tile
+	LastTile       Tile
+	LastTilePlayer seat
+	KanCount       uint8
+	KanDora        uint8
+	PendingKanDora uint8
+	Chankan        *chankanSnapshot
+	ClaimWindow    *claimWindowSnapshot
+	GameOver       []placementSnapshot
+	Ended          bool
+	Events         []Event
+	Deposit        int
+}
+
+func (maj *Mahjong) snapshot() (*snapshot, error) {
+	if maj.source == nil || maj.Seed == nil {
+		return nil, errors.New("Seed can not be saved. ")
+	}
+	s := &snapshot{
+		Round:          maj.Round,
+		Seed:           maj.source.seed,
+		SeedCount:      maj.source.count,
+		Now:            maj.Players.Now().FieldWind,
+		ResultType:     maj.Result.ResultType,
+		Ryuukyoku:      maj.Result.Ryuukyoku,
+		Renchan:        maj.Result.Renchan,
+		NextTile:       maj.NextTile,
+		Tiles:          maj.Tiles,
+		LastTile:       maj.LastTile,
+		LastTilePlayer: seatOf(maj.LastTilePlayer),
+		KanCount:       maj.KanCount,
+		KanDora:        maj.KanDora,
+		PendingKanDora: maj.PendingKanDora,
+		Events:         maj.Events,
+		Deposit:        maj.Deposit,
+	}
+	for seat := EastField; int(seat) < maj.Players.ring.Len(); seat++ {
+		player := maj.Players.FindField(seat)
+		if player == nil {
+			return nil, errors.New("Players are not seated in order. ")
+		}
+		s.Players = append(
+			s.Players, playerSnapshot{Player: *player, Jun: player.jun, Rinshan: player.rinshan, Ippatsu: player.ippatsu},
+		)
+	}
+	for _, win := range maj.Result.Wins {
+		w := winSnapshot{WinResult: *win, Winner: seatOf(win.Winner), Discarder: seatOf(win.Discarder)}
+		w.WinResult.Winner, w.WinResult.Discarder = nil, nil
+		s.Wins = append(s.Wins, w)
+	}
+	if chankan := maj.Chankan; chankan != nil {
+		s.Chankan = &chankanSnapshot{Player: seatOf(chankan.Player), Tile: chankan.Tile, Concealed: chankan.Concealed}
+	}
+	if window := maj.ClaimWindow; window != nil {
+		s.ClaimWindow = &claimWindowSnapshot{
+			Tile: window.Tile, Discarder: seatOf(window.Discarder), Options: window.Options, Claims: window.Claims,
+		}
+	}
+	if maj.GameOver != nil {
+		s.Ended = true
+		for _, placement := range maj.GameOver.Placements {
+			p := placementSnapshot{Placement: placement, Player: seatOf(placement.Player)}
+			p.Placement.Player = nil
+			s.GameOver = append(s.GameOver, p)
+		}
+	}
+	return s, nil
+}
+
+//ルールはそのまま使うので、同じルールで作ったMahjongに読み込む
+func (maj *Mahjong) restore(s *snapshot) error {
+	if maj.Rule == nil {
+		return errors.New("Rule is not set. ")
+	}
+	if len(s.Players) == 0 {
+		return errors.New("Snapshot has no player. ")
+	}
+	r := ring.New(len(s.Players))
+	for i := range s.Players {
+		player := s.Players[i].Player
+		player.jun, player.rinshan, player.ippatsu = s.Players[i].Jun, s.Players[i].Rinshan, s.Players[i].Ippatsu
+		if player.FieldWind != FieldWind(i) {
+			return errors.New("Players are not seated in order. ")
+		}
+		r.Value = &player
+		r = r.Next()
+	}
+	restored := Mahjong{
+		Round:          s.Round,
+		Rule:           maj.Rule,
+		Listener:       maj.Listener,
+		Players:        Players{r},
+		Result:         Result{ResultType: s.ResultType, Ryuukyoku: s.Ryuukyoku, Renchan: s.Renchan},
+		NextTile:       s.NextTile,
+		Tiles:          s.Tiles,
+		LastTile:       s.LastTile,
+		KanCount:       s.KanCount,
+		KanDora:        s.KanDora,
+		PendingKanDora: s.PendingKanDora,
+		Events:         s.Events,
+		Deposit:        s.Deposit,
+	}
+	restored.source = newSeedSource(s.Seed, s.SeedCount)
+	restored.Seed = rand.New(restored.source)
+	now := restored.Players.FindField(s.Now)
+	if now == nil {
+		return errors.New("Snapshot refers to an empty seat. ")
+	}
+	restored.Players.Set(now)
+
+	var err error
+	if restored.LastTilePlayer, err = restored.playerAt(s.LastTilePlayer); err != nil {
+		return err
+	}
+	for _, w := range s.Wins {
+		win := w.WinResult
+		if win.Winner, err = restored.playerAt(w.Winner); err != nil {
+			return err
+		}
+		if win.Discarder, err = restored.playerAt(w.Discarder); err != nil {
+			return err
+		}
+		restored.Result.Wins = append(restored.Result.Wins, &win)
+	}
+	if c := s.Chankan; c != nil {
+		restored.Chankan = &Chankan{Tile: c.Tile, Concealed: c.Concealed}
+		if restored.Chankan.Player, err = restored.playerAt(c.Player); err != nil {
+			return err
+		}
+	}
+	if w := s.ClaimWindow; w != nil {
+		restored.ClaimWindow = &ClaimWindow{Tile: w.Tile, Options: w.Options, Claims: w.Claims}
+		if restored.ClaimWindow.Discarder, err = restored.playerAt(w.Discarder); err != nil {
+			return err
+		}
+		if restored.ClaimWindow.Options == nil {
+			restored.ClaimWindow.Options = make(map[FieldWind][]ClaimType)
+		}
+		if restored.ClaimWindow.Claims == nil {
+			restored.ClaimWindow.Claims = make(map[FieldWind]Claim)
+		}
+	}
+	if s.Ended {
+		restored.GameOver = &GameOver{}
+		for _, p := range s.GameOver {
+			placement := p.Placement
+			if placement.Player, err = restored.playerAt(p.Player); err != nil {
+				return err
+			}
+			restored.GameOver.Placements = append(restored.GameOver.Placements, placement)
+		}
+	}
+	*maj = restored
+	maj.Rule.Init(maj)
+	return nil
+}
+
+func (maj *Mahjong) MarshalJSON() ([]byte, error) {
+	s, err := maj.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+func (maj *Mahjong) UnmarshalJSON(data []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return maj.restore(&s)
+}
+
+//gobで書く、JSONより小さい
+func (maj *Mahjong) MarshalBinary() ([]byte, error) {
+	s, err := maj.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (maj *Mahjong) UnmarshalBinary(data []byte) error {
+	var s snapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+	return maj.restore(&s)
+}
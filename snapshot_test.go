@@ -0,0 +1,124 @@
+package mahjong
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+//局の途中で鳴きの受付中、局の終了後、終局後
+func snapshotGames(t *testing.T) map[string]*Mahjong {
+	claiming := newTestGame(t)
+	playHands(t, claiming, 1)
+	if err := claiming.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	for claiming.ClaimWindow == nil {
+		playStep(t, claiming)
+	}
+
+	ended := newTestGame(t)
+	playHands(t, ended, 2)
+
+	over := newTestGame(t)
+	playHands(t, over, 1)
+	over.GameOver = &GameOver{Placements: over.Placements()}
+	return map[string]*Mahjong{"claiming": claiming, "ended": ended, "over": over}
+}
+
+func checkRestored(t *testing.T, maj, restored *Mahjong) {
+	want, err := json.Marshal(maj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("restored = %s, want %s", got, want)
+	}
+	if restored.LastTilePlayer != restored.Players.FindField(maj.LastTilePlayer.FieldWind) {
+		t.Error("LastTilePlayer should point to a restored player")
+	}
+	if restored.Players.Now().FieldWind != maj.Players.Now().FieldWind {
+		t.Errorf("Now() = %v, want %v", restored.Players.Now().FieldWind, maj.Players.Now().FieldWind)
+	}
+	if maj.GameOver != nil {
+		return
+	}
+
+	//乱数の位置も戻っていれば続きが同じになる
+	for _, m := range []*Mahjong{maj, restored} {
+		for !m.Result.Done() {
+			playStep(t, m)
+		}
+		playHands(t, m, 2)
+	}
+	if !reflect.DeepEqual(restored.Events, maj.Events) {
+		t.Errorf("Events differ after restore, %v/%v", len(restored.Events), len(maj.Events))
+	}
+	if restored.Seed.Int63() != maj.Seed.Int63() {
+		t.Error("Seed should continue from the same position")
+	}
+}
+
+func TestMahjong_MarshalJSON(t *testing.T) {
+	for name, maj := range snapshotGames(t) {
+		t.Run(
+			name, func(t *testing.T) {
+				data, err := json.Marshal(maj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				restored := InitWithSeed(&JapaneseHanChanRule{}, 99)
+				if err := json.Unmarshal(data, restored); err != nil {
+					t.Fatal(err)
+				}
+				checkRestored(t, maj, restored)
+			},
+		)
+	}
+}
+
+func TestMahjong_MarshalBinary(t *testing.T) {
+	for name, maj := range snapshotGames(t) {
+		t.Run(
+			name, func(t *testing.T) {
+				data, err := maj.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				text, _ := json.Marshal(maj)
+				if len(data) >= len(text) {
+					t.Errorf("binary = %v bytes, json = %v bytes", len(data), len(text))
+				}
+				restored := InitWithSeed(&JapaneseHanChanRule{}, 99)
+				if err := restored.UnmarshalBinary(data); err != nil {
+					t.Fatal(err)
+				}
+				checkRestored(t, maj, restored)
+			},
+		)
+	}
+}
+
+func TestMahjong_UnmarshalJSON_Invalid(t *testing.T) {
+	data, err := json.Marshal(newTestGame(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//ルールがないと復元できない
+	if err := json.Unmarshal(data, &Mahjong{}); err == nil {
+		t.Error("UnmarshalJSON() without a rule should fail")
+	}
+	for _, data := range []string{`{}`, `{"Players":[{"FieldWind":1}]}`, `{"Players":[{}],"Now":2}`} {
+		if err := json.Unmarshal([]byte(data), InitWithSeed(&JapaneseHanChanRule{}, 1)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) should fail", data)
+		}
+	}
+	if _, err := json.Marshal(&Mahjong{}); err == nil {
+		t.Error("MarshalJSON() without a seed should fail")
+	}
+}
@@ -0,0 +1,376 @@
+package mahjong
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//天鳳の牌譜(tenhou.net/6)
+type tenhouGame struct {
+	Log [][]json.RawMessage `json:"log"`
+}
+
+//11-19 萬子、21-29 筒子、31-39 索子、41-47 字牌、51-53 赤五
+func tenhouTile(code int) (Tile, error) {
+	switch {
+	case code >= 51 && code <= 53:
+		return Tile{TileType: []TileType{Characters5, Dots5, Bamboo5}[code-51]}, nil
+	case code >= 11 && code <= 19:
+		return logTile(Characters1 + TileType(code-11)), nil
+	case code >= 21 && code <= 29:
+		return logTile(Dots1 + TileType(code-21)), nil
+	case code >= 31 && code <= 39:
+		return logTile(Bamboo1 + TileType(code-31)), nil
+	case code >= 41 && code <= 47:
+		return logTile(East + TileType(code-41)), nil
+	}
+	return Tile{}, errors.New("Invalid tenhou tile " + strconv.Itoa(code) + ". ")
+}
+
+//赤でない牌
+func logTile(tileType TileType) Tile {
+	return Tile{TileType: tileType, Id: 1}
+}
+
+func tenhouTiles(codes []int) ([]Tile, error) {
+	tiles := make([]Tile, 0, len(codes))
+	for _, code := range codes {
+		tile, err := tenhouTile(code)
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles, nil
+}
+
+//鳴きの文字列、記号の直後が鳴いた牌か加えた牌
+type tenhouMeld struct {
+	Marker   byte
+	Position int
+	Tile
+	Others []Tile
+}
+
+func parseTenhouMeld(str string) (*tenhouMeld, error) {
+	meld := &tenhouMeld{}
+	for i := 0; i < len(str); {
+		if str[i] < '0' || str[i] > '9' {
+			meld.Marker = str[i]
+			meld.Position = i
+			i++
+			continue
+		}
+		if i+2 > len(str) {
+			return nil, errors.New("Invalid tenhou meld " + str + ". ")
+		}
+		code, err := strconv.Atoi(str[i : i+2])
+		if err != nil {
+			return nil, err
+		}
+		tile, err := tenhouTile(code)
+		if err != nil {
+			return nil, err
+		}
+		if meld.Marker != 0 && meld.Position == i-1 {
+			meld.Tile = tile
+		} else {
+			meld.Others = append(meld.Others, tile)
+		}
+		i += 2
+	}
+	if meld.Marker == 0 {
+		return nil, errors.New("Invalid tenhou meld " + str + ". ")
+	}
+	return meld, nil
+}
+
+//鳴いた相手、ポン・大明槓は記号の位置で上家・対面・下家
+func (meld *tenhouMeld) from(caller int) int {
+	switch {
+	case meld.Marker == 'c' || meld.Position == 0:
+		return (caller + 3) % 4
+	case meld.Position == 2:
+		return (caller + 2) % 4
+	}
+	return (caller + 1) % 4
+}
+
+func ParseTenhou(data []byte) (*GameLog, error) {
+	var game tenhouGame
+	if err := json.Unmarshal(data, &game); err != nil {
+		return nil, err
+	}
+	log := &GameLog{}
+	for _, raw := range game.Log {
+		hand, err := parseTenhouHand(raw)
+		if err != nil {
+			return nil, err
+		}
+		log.Hands = append(log.Hands, *hand)
+	}
+	return log, nil
+}
+
+func parseTenhouHand(raw []json.RawMessage) (*HandLog, error) {
+	if len(raw) != 17 {
+		return nil, errors.New("Tenhou hand needs 17 entries. ")
+	}
+	var info [3]int
+	var scores [4]int
+	var dora, ura []int
+	for i, v := range []interface{}{&info, &scores, &dora, &ura} {
+		if err := json.Unmarshal(raw[i], v); err != nil {
+			return nil, err
+		}
+	}
+	hand := &HandLog{
+		Round:   Round{FieldWind: FieldWind(info[0] / 4), Number: int8(info[0] % 4), Honba: int8(info[1])},
+		Deposit: info[2] * RiichiDeposit,
+		Scores:  scores,
+	}
+	var err error
+	if hand.DoraIndicators, err = tenhouTiles(dora); err != nil {
+		return nil, err
+	}
+	if hand.UraDoraIndicators, err = tenhouTiles(ura); err != nil {
+		return nil, err
+	}
+	var takes, discards [4][]interface{}
+	for seat := 0; seat < 4; seat++ {
+		var haipai []int
+		if err := json.Unmarshal(raw[4+3*seat], &haipai); err != nil {
+			return nil, err
+		}
+		if hand.Haipai[seat], err = tenhouTiles(haipai); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw[5+3*seat], &takes[seat]); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw[6+3*seat], &discards[seat]); err != nil {
+			return nil, err
+		}
+	}
+	var result []interface{}
+	if err := json.Unmarshal(raw[16], &result); err != nil {
+		return nil, err
+	}
+	last, err := hand.tenhouActions(int(hand.Number), takes, discards)
+	if err != nil {
+		return nil, err
+	}
+	if err := hand.tenhouResult(result, last); err != nil {
+		return nil, err
+	}
+	return hand, nil
+}
+
+//ツモと打牌の列を手番順に並べる
+func (hand *HandLog) tenhouActions(dealer int, takes, discards [4][]interface{}) (int, error) {
+	var ti, di [4]int
+	nextTake := func(seat int) interface{} {
+		if ti[seat] >= len(takes[seat]) {
+			return nil
+		}
+		ti[seat]++
+		return takes[seat][ti[seat]-1]
+	}
+	seat := dealer
+	var drawn Tile
+	for {
+		take := nextTake(seat)
+		if take == nil {
+			return seat, nil
+		}
+		switch take := take.(type) {
+		case float64:
+			tile, err := tenhouTile(int(take))
+			if err != nil {
+				return 0, err
+			}
+			drawn = tile
+			hand.Actions = append(hand.Actions, LogAction{Type: LogDraw, Seat: FieldWind(seat), Tile: tile})
+		case string:
+			meld, err := parseTenhouMeld(take)
+			if err != nil {
+				return 0, err
+			}
+			switch meld.Marker {
+			case 'c':
+				hand.Actions = append(hand.Actions, LogAction{LogChii, FieldWind(seat), meld.Tile, meld.Others})
+			case 'p':
+				hand.Actions = append(hand.Actions, LogAction{LogPon, FieldWind(seat), meld.Tile, meld.Others})
+			case 'm':
+				hand.Actions = append(hand.Actions, LogAction{Type: LogKan, Seat: FieldWind(seat), Tile: meld.Tile})
+				//打牌の列には0が入り、次のツモが嶺上牌
+				di[seat]++
+				if drawn, err = hand.tenhouDrawKan(seat, nextTake(seat)); err != nil {
+					return 0, err
+				}
+			default:
+				return 0, errors.New("Invalid tenhou call " + take + ". ")
+			}
+		}
+
+		discard, err := hand.tenhouDiscard(seat, drawn, discards[seat], &di[seat], nextTake)
+		if err != nil {
+			return 0, err
+		}
+		if discard.TileType == None {
+			return seat, nil
+		}
+		seat = tenhouCaller(seat, discard, takes, ti)
+	}
+}
+
+func (hand *HandLog) tenhouDrawKan(seat int, take interface{}) (Tile, error) {
+	code, ok := take.(float64)
+	if !ok {
+		return Tile{}, errors.New("No rinshan tile after kan. ")
+	}
+	tile, err := tenhouTile(int(code))
+	if err != nil {
+		return Tile{}, err
+	}
+	hand.Actions = append(hand.Actions, LogAction{Type: LogDrawKan, Seat: FieldWind(seat), Tile: tile})
+	return tile, nil
+}
+
+//暗槓・加槓なら嶺上牌を引いてから打牌する、60はツモ切り
+func (hand *HandLog) tenhouDiscard(
+	seat int, drawn Tile, discards []interface{}, di *int, nextTake func(int) interface{},
+) (Tile, error) {
+	for *di < len(discards) {
+		discard := discards[*di]
+		*di++
+		action := LogAction{Type: LogDiscard, Seat: FieldWind(seat)}
+		code := 0
+		switch discard := discard.(type) {
+		case float64:
+			code = int(discard)
+		case string:
+			if strings.HasPrefix(discard, "r") {
+				action.Type = LogRiichi
+				var err error
+				if code, err = strconv.Atoi(discard[1:]); err != nil {
+					return Tile{}, err
+				}
+				break
+			}
+			meld, err := parseTenhouMeld(discard)
+			if err != nil {
+				return Tile{}, err
+			}
+			switch meld.Marker {
+			case 'a':
+				hand.Actions = append(hand.Actions, LogAction{Type: LogAnKan, Seat: FieldWind(seat), Tile: meld.Tile})
+			case 'k':
+				hand.Actions = append(hand.Actions, LogAction{Type: LogKaKan, Seat: FieldWind(seat), Tile: meld.Tile})
+			default:
+				return Tile{}, errors.New("Invalid tenhou discard " + discard + ". ")
+			}
+			take := nextTake(seat)
+			//槍槓で終わった
+			if take == nil {
+				return Tile{}, nil
+			}
+			if drawn, err = hand.tenhouDrawKan(seat, take); err != nil {
+				return Tile{}, err
+			}
+			continue
+		}
+		if code == 60 {
+			action.Tile = drawn
+		} else {
+			tile, err := tenhouTile(code)
+			if err != nil {
+				return Tile{}, err
+			}
+			action.Tile = tile
+		}
+		hand.Actions = append(hand.Actions, action)
+		return action.Tile, nil
+	}
+	return Tile{}, nil
+}
+
+//ポン・大明槓が優先、なければ下家のチー、誰も鳴かなければ下家
+func tenhouCaller(discarder int, discard Tile, takes [4][]interface{}, ti [4]int) int {
+	next := (discarder + 1) % 4
+	chii := -1
+	for offset := 1; offset < 4; offset++ {
+		seat := (discarder + offset) % 4
+		if ti[seat] >= len(takes[seat]) {
+			continue
+		}
+		str, ok := takes[seat][ti[seat]].(string)
+		if !ok {
+			continue
+		}
+		meld, err := parseTenhouMeld(str)
+		if err != nil || meld.TileType != discard.TileType || meld.from(seat) != discarder {
+			continue
+		}
+		if meld.Marker == 'c' {
+			chii = seat
+			continue
+		}
+		return seat
+	}
+	if chii >= 0 {
+		return chii
+	}
+	return next
+}
+
+//和了は [和了, 点数移動, 情報...]、情報の先頭は和了者・放銃者
+func (hand *HandLog) tenhouResult(result []interface{}, last int) error {
+	if len(result) == 0 {
+		return errors.New("Tenhou hand has no result. ")
+	}
+	name, _ := result[0].(string)
+	deltas := func(i int) error {
+		if i >= len(result) {
+			return nil
+		}
+		values, ok := result[i].([]interface{})
+		if !ok || len(values) < 4 {
+			return errors.New("Invalid tenhou deltas. ")
+		}
+		for seat := 0; seat < 4; seat++ {
+			delta, _ := values[seat].(float64)
+			hand.Deltas[seat] += int(delta)
+		}
+		return nil
+	}
+	switch name {
+	case "和了":
+		for i := 1; i+1 < len(result); i += 2 {
+			if err := deltas(i); err != nil {
+				return err
+			}
+			info, ok := result[i+1].([]interface{})
+			if !ok || len(info) < 2 {
+				return errors.New("Invalid tenhou agari. ")
+			}
+			who, _ := info[0].(float64)
+			from, _ := info[1].(float64)
+			action := LogAction{Type: LogRon, Seat: FieldWind(who)}
+			if who == from {
+				action.Type = LogTsumo
+			}
+			hand.Actions = append(hand.Actions, action)
+		}
+		return nil
+	case "九種九牌":
+		hand.Actions = append(hand.Actions, LogAction{Type: LogNineYaochus, Seat: FieldWind(last)})
+	case "三家和了":
+		hand.Actions = append(hand.Actions, LogAction{Type: LogTripleRon})
+	default:
+		hand.Actions = append(hand.Actions, LogAction{Type: LogRyuukyoku})
+	}
+	return deltas(1)
+}
@@ -0,0 +1,80 @@
+package mahjong
+
+import (
+	"strings"
+	"testing"
+)
+
+//東1局 南家のチーから西家が8mでロン、東2局 親の南家がダブル立直一発ツモ
+const tenhouSample = `{"title":["",""],"name":["A","B","C","D"],"rule":{"disp":"般南喰赤","aka":1},"log":[
+[[0,0,0],[25000,25000,25000,25000],[47],[],
+[11,19,21,29,31,33,39,41,42,43,44,45,46],[47],[33],
+[34,35,18,11,19,21,29,31,39,41,42,43,44],["c333435"],[18],
+[22,23,24,25,26,27,32,33,34,16,16,16,17],[],[],
+[11,19,21,29,31,39,41,42,43,44,45,46,47],[],[],
+["和了",[0,-2000,2000,0],[2,1,2,"30符2飜2000点","断幺九(1飜)","平和(1飜)"]]],
+[[1,0,0],[25000,23000,27000,25000],[47],[41],
+[13,16,19,21,24,27,32,35,38,42,43,46,47],[39],[60],
+[12,13,14,24,25,26,36,37,38,22,22,33,34],[41,35],["r60"],
+[11,14,17,22,25,28,33,36,39,42,43,44,45],[19],[60],
+[12,15,18,23,26,29,31,34,37,42,43,44,46],[29],[60],
+["和了",[-6000,19000,-6000,-6000],[1,1,1,"6飜跳満6000点∀","ダブル立直(2飜)","一発(1飜)","門前清自摸和(1飜)","断幺九(1飜)","平和(1飜)"]]]
+]}`
+
+func TestParseTenhou(t *testing.T) {
+	log, err := ParseTenhou([]byte(tenhouSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Hands) != 2 {
+		t.Fatalf("len(Hands) = %v", len(log.Hands))
+	}
+	wants := [][]LogActionType{
+		{LogDraw, LogDiscard, LogChii, LogDiscard, LogRon},
+		{LogDraw, LogRiichi, LogDraw, LogDiscard, LogDraw, LogDiscard, LogDraw, LogDiscard, LogDraw, LogTsumo},
+	}
+	for i, want := range wants {
+		hand := log.Hands[i]
+		if len(hand.Actions) != len(want) {
+			t.Fatalf("Hands[%v].Actions = %+v", i, hand.Actions)
+		}
+		for j, action := range hand.Actions {
+			if action.Type != want[j] {
+				t.Errorf("Hands[%v].Actions[%v] = %+v, want %v", i, j, action, want[j])
+			}
+		}
+	}
+	if chii := log.Hands[0].Actions[2]; chii.Seat != SouthField || chii.TileType != Bamboo3 || len(chii.Consumed) != 2 {
+		t.Errorf("chii = %+v", chii)
+	}
+}
+
+func TestReplayLog_Tenhou(t *testing.T) {
+	log, err := ParseTenhou([]byte(tenhouSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := &JapaneseHanChanRule{JapaneseBaseRule{Options: &TenhouRuleOptions}}
+	report := ReplayLog(rule, log)
+	if report.Hands != 2 || len(report.Divergences) != 0 {
+		t.Errorf("ReplayLog() = %+v", report)
+	}
+
+	//記録と違う点数は食い違いとして報告する
+	log.Hands[0].Deltas = [4]int{0, -3900, 3900, 0}
+	report = ReplayLog(rule, log)
+	if len(report.Divergences) != 1 {
+		t.Fatalf("ReplayLog() = %+v", report)
+	}
+	divergence := report.Divergences[0]
+	if divergence.Hand != 0 || divergence.Engine != [4]int{0, -2000, 2000, 0} || divergence.Err != nil {
+		t.Errorf("Divergence = %v", divergence)
+	}
+}
+
+func TestParseTenhou_Invalid(t *testing.T) {
+	broken := strings.Replace(tenhouSample, `"c333435"`, `"x333435"`, 1)
+	if _, err := ParseTenhou([]byte(broken)); err == nil {
+		t.Error("ParseTenhou() with an unknown call should fail")
+	}
+}
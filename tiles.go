@@ -83,6 +83,25 @@ func (tileType TileType) IsDragon() bool {
 	return true
 }
 
+func (tileType TileType) IsFlower() bool {
+	if tileType > Winter || tileType < PlumBlossom {
+		return false
+	}
+	return true
+}
+
+//百三十六枚と花牌八枚
+func tilesWithFlowers() []Tile {
+	tiles := make([]Tile, 0, 144)
+	for i := 0; i < 136; i++ {
+		tiles = append(tiles, Tile{TileType: TileType(i/4 + 1), Id: int8(i % 4)})
+	}
+	for tileType := PlumBlossom; tileType <= Winter; tileType++ {
+		tiles = append(tiles, Tile{TileType: tileType})
+	}
+	return tiles
+}
+
 func (tileType TileType) IsTerminals() bool {
 	if tileType != Bamboo1 && tileType != Bamboo9 && tileType != Dots1 &&
 		tileType != Dots9 && tileType != Characters1 && tileType != Characters9 {
@@ -107,6 +126,23 @@ func (tileType TileType) Number() int8 {
 	return n
 }
 
+//ドラ表示牌の次の牌
+func (tileType TileType) Dora() TileType {
+	switch {
+	case tileType.IsSuit():
+		if tileType.Number() == 9 {
+			return tileType - 8
+		}
+	case tileType == North:
+		return East
+	case tileType == Red:
+		return White
+	case !tileType.IsHonor():
+		return None
+	}
+	return tileType + 1
+}
+
 func (tileType TileType) IsYaochu() bool {
 	return !((tileType > Dots1 && tileType < Dots9) ||
 		(tileType > Bamboo1 && tileType < Bamboo9) ||
@@ -245,6 +281,9 @@ type DiscardTile struct {
 	Tile
 	Jun
 	TsumoGiri bool
+	Riichi    bool
+	//鳴かれた
+	Called bool
 }
 
 type FuuroType int8
@@ -352,4 +391,13 @@ var TilesName = map[TileType]string{
 	White:       "5z", //🀆
 	Green:       "6z", //🀅
 	Red:         "7z", //🀄
+
+	PlumBlossom:   "1f", //🀢
+	Orchid:        "2f", //🀣
+	Chrysanthemum: "3f", //🀥
+	Bamboo:        "4f", //🀤
+	Spring:        "5f", //🀦
+	Summer:        "6f", //🀧
+	Autumn:        "7f", //🀨
+	Winter:        "8f", //🀩
 }
@@ -0,0 +1,27 @@
+package mahjong
+
+import "testing"
+
+func TestTileType_Dora(t *testing.T) {
+	tests := []struct {
+		indicator TileType
+		want      TileType
+	}{
+		{Dots1, Dots2},
+		{Dots9, Dots1},
+		{Bamboo9, Bamboo1},
+		{Characters5, Characters6},
+		{Characters9, Characters1},
+		{East, South},
+		{North, East},
+		{White, Green},
+		{Red, White},
+		{None, None},
+		{PlumBlossom, None},
+	}
+	for _, tt := range tests {
+		if got := tt.indicator.Dora(); got != tt.want {
+			t.Errorf("%v.Dora() = %v, want %v", tt.indicator, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package mahjong
+
+import (
+	"errors"
+	"strconv"
+)
+
+//他家から見える席の情報
+type SeatView struct {
+	FieldWind
+	//その局の自風
+	Wind      FieldWind
+	Score     int
+	TileCount int
+	Melds     []Meld
+	//抜いた北
+	Kita       []Tile
+	Flowers    []Tile
+	Discards   []DiscardTile
+	Riichi     bool
+	OpenRiichi bool
+	//オープン立直なら手牌も見える
+	Tiles []Tile `json:",omitempty"`
+}
+
+//一人のプレイヤーに見せてよい情報、他家の手牌と山は含まない
+type View struct {
+	Seat FieldWind
+	Round
+	Deposit int
+	Turn    FieldWind
+	Phase
+	Hand     []Tile
+	LastDraw Tile
+	Seats    []SeatView
+	//めくられたドラ表示牌
+	DoraIndicators []Tile
+	//ツモれる残り枚数
+	RemainingTiles uint8
+	Actions        *PlayerActions
+	//鳴きの受付中なら宣言できるもの
+	Claims []ClaimType `json:",omitempty"`
+	ResultType
+	GameOver bool
+}
+
+func (maj *Mahjong) ViewFor(seat FieldWind) (*View, error) {
+	player := maj.Players.FindField(seat)
+	if player == nil {
+		return nil, errors.New("No player at seat " + strconv.Itoa(int(seat)) + ". ")
+	}
+	view := &View{
+		Seat:       seat,
+		Round:      maj.Round,
+		Deposit:    maj.Deposit,
+		Turn:       maj.Players.Now().FieldWind,
+		Phase:      player.Phase,
+		Hand:       SortTiles(append([]Tile(nil), player.Tiles...)),
+		LastDraw:   player.LastDraw,
+		ResultType: maj.Result.ResultType,
+		GameOver:   maj.GameOver != nil,
+		Actions:    &PlayerActions{},
+	}
+	if len(maj.Tiles) != 0 {
+		view.DoraIndicators = maj.Rule.DoraIndicators()
+		view.RemainingTiles = maj.RemainderTilesCanDraw()
+	}
+	for s := EastField; int(s) < maj.Players.ring.Len(); s++ {
+		if p := maj.Players.FindField(s); p != nil {
+			view.Seats = append(view.Seats, seatView(p, maj.Round))
+		}
+	}
+	if maj.checkPlaying() != nil || len(maj.Tiles) == 0 {
+		return view, nil
+	}
+	view.Actions = maj.PlayerCan(player)
+	if window := maj.ClaimWindow; window != nil {
+		if _, declared := window.Claims[seat]; !declared && window.CanClaim(player, ClaimPass) {
+			view.Claims = append([]ClaimType{ClaimPass}, window.Options[seat]...)
+		}
+	}
+	return view, nil
+}
+
+func seatView(player *Player, round Round) SeatView {
+	view := SeatView{
+		FieldWind:  player.FieldWind,
+		Wind:       player.Wind(round),
+		Score:      player.Score,
+		TileCount:  len(player.Tiles),
+		Melds:      player.melds(),
+		Kita:       append([]Tile(nil), player.Kita...),
+		Flowers:    append([]Tile(nil), player.Flowers...),
+		Discards:   append([]DiscardTile(nil), player.Discards...),
+		Riichi:     !player.Riichi.First(),
+		OpenRiichi: player.OpenRiichi,
+	}
+	if player.OpenRiichi {
+		view.Tiles = SortTiles(append([]Tile(nil), player.Tiles...))
+	}
+	return view
+}
+
+//見えている牌、Mahjong.VisibleTilesと同じ数え方
+func (view *View) VisibleTiles() [Red + 1]int {
+	var visible [Red + 1]int
+	add := func(tileType TileType) {
+		if tileType >= Dots1 && tileType <= Red {
+			visible[tileType]++
+		}
+	}
+	for _, tile := range view.Hand {
+		add(tile.TileType)
+	}
+	for _, seat := range view.Seats {
+		for _, discard := range seat.Discards {
+			if !discard.Called {
+				add(discard.TileType)
+			}
+		}
+		for _, meld := range seat.Melds {
+			for _, tile := range meld.Tiles {
+				add(tile.TileType)
+			}
+		}
+		for _, tile := range seat.Kita {
+			add(tile.TileType)
+		}
+	}
+	if len(view.Seats) == 3 {
+		for tileType := Characters2; tileType < Characters9; tileType++ {
+			visible[tileType] += 4
+		}
+	}
+	for _, indicator := range view.DoraIndicators {
+		add(indicator.TileType)
+	}
+	return visible
+}
@@ -0,0 +1,98 @@
+package mahjong
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMahjong_ViewFor(t *testing.T) {
+	maj := newTestGame(t)
+	parent := maj.Players.Now()
+	view, err := maj.ViewFor(parent.FieldWind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.Hand) != 14 || view.Turn != parent.FieldWind || !view.Actions.Dahai || view.Actions.Draw {
+		t.Errorf("view = %+v", view)
+	}
+	if len(view.Seats) != 4 || len(view.DoraIndicators) != 1 || view.RemainingTiles != 69 {
+		t.Errorf("seats = %v, dora = %v, remaining = %v", len(view.Seats), view.DoraIndicators, view.RemainingTiles)
+	}
+	for _, seat := range view.Seats {
+		if seat.Tiles != nil || seat.Wind != maj.Players.FindField(seat.FieldWind).Wind(maj.Round) {
+			t.Errorf("seat %v = %+v", seat.FieldWind, seat)
+		}
+	}
+
+	if err := maj.Dahai(parent, parent.LastDraw); err != nil {
+		t.Fatal(err)
+	}
+	child := maj.Players.Now()
+	view, err = maj.ViewFor(child.FieldWind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	discards := view.Seats[parent.FieldWind].Discards
+	if len(discards) != 1 || !discards[0].TsumoGiri || view.Seats[parent.FieldWind].TileCount != 13 {
+		t.Errorf("discards = %+v", discards)
+	}
+	if len(view.Hand) != 13 || !view.Actions.Draw || view.Actions.Dahai {
+		t.Errorf("view = %+v", view)
+	}
+
+	if _, err := maj.ViewFor(FieldWind(5)); err == nil {
+		t.Error("ViewFor() an empty seat should fail")
+	}
+}
+
+func TestMahjong_ViewFor_OpenRiichi(t *testing.T) {
+	maj := newTestGame(t)
+	south := maj.Players.FindField(SouthField)
+	south.Riichi = 1
+	view, err := maj.ViewFor(EastField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seat := view.Seats[SouthField]; !seat.Riichi || seat.Tiles != nil {
+		t.Errorf("riichi seat = %+v", seat)
+	}
+
+	south.OpenRiichi = true
+	if view, err = maj.ViewFor(EastField); err != nil {
+		t.Fatal(err)
+	}
+	if seat := view.Seats[SouthField]; !reflect.DeepEqual(seat.Tiles, SortTiles(append([]Tile(nil), south.Tiles...))) {
+		t.Errorf("open riichi tiles = %v", seat.Tiles)
+	}
+}
+
+func TestMahjong_ViewFor_Claims(t *testing.T) {
+	maj := newTestGame(t)
+	for maj.ClaimWindow == nil {
+		playStep(t, maj)
+	}
+	for seat, options := range maj.ClaimWindow.Options {
+		view, err := maj.ViewFor(seat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := append([]ClaimType{ClaimPass}, options...); !reflect.DeepEqual(view.Claims, want) {
+			t.Errorf("Claims = %v, want %v", view.Claims, want)
+		}
+		if _, err := maj.Declare(maj.Players.FindField(seat), Claim{ClaimType: ClaimPass}); err != nil {
+			t.Fatal(err)
+		}
+		if view, _ = maj.ViewFor(seat); view.Claims != nil {
+			t.Errorf("Claims after declaring = %v", view.Claims)
+		}
+	}
+
+	playHands(t, maj, 1)
+	view, err := maj.ViewFor(EastField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.ResultType == NoResult || !reflect.DeepEqual(view.Actions, &PlayerActions{}) {
+		t.Errorf("view after the hand = %+v", view)
+	}
+}
@@ -0,0 +1,171 @@
+package mahjong
+
+//待ち牌、残り枚数は見えていない牌の数
+type WaitingTile struct {
+	TileType
+	Waits     []WaitType
+	Remaining int
+}
+
+//受け入れ、向聴数を進める牌
+type Ukeire struct {
+	Shanten int
+	Tiles   []WaitingTile
+	Total   int
+}
+
+//見えている牌、自分の手牌・全員の河と副露・ドラ表示牌
+func (maj *Mahjong) VisibleTiles(player *Player) [Red + 1]int {
+	var visible [Red + 1]int
+	add := func(tileType TileType, n int) {
+		if tileType >= Dots1 && tileType <= Red {
+			visible[tileType] += n
+		}
+	}
+	for _, tile := range player.Tiles {
+		add(tile.TileType, 1)
+	}
+	maj.Players.Do(
+		func(p *Player) {
+			for _, discard := range p.Discards {
+				//鳴かれた牌は副露で数える
+				if !discard.Called {
+					add(discard.TileType, 1)
+				}
+			}
+			for _, xxx := range p.XXXs {
+				for _, tile := range xxx.TilesXXX {
+					add(tile.TileType, 1)
+				}
+			}
+			for _, xyz := range p.XYZs {
+				for _, tile := range xyz.TilesXYZ {
+					add(tile.TileType, 1)
+				}
+			}
+			for _, xxxx := range p.XXXXs {
+				add(xxxx.TilesXXXX, 4)
+			}
+			for _, tile := range p.Kita {
+				add(tile.TileType, 1)
+			}
+		},
+	)
+	//三人麻雀で使わない萬子は全て見えているものとする
+	if maj.Players.Len() == 3 {
+		for tileType := Characters2; tileType < Characters9; tileType++ {
+			add(tileType, 4)
+		}
+	}
+	for _, indicator := range maj.Rule.DoraIndicators() {
+		add(indicator.TileType, 1)
+	}
+	return visible
+}
+
+//向聴数が下がる牌を数える
+func CountUkeire(tileTypes []TileType, melds int, visible [Red + 1]int) Ukeire {
+	shanten := CountShanten(tileTypes, melds).Min()
+	ukeire := Ukeire{Shanten: shanten}
+	added := make([]TileType, len(tileTypes)+1)
+	copy(added, tileTypes)
+	for tileType := Dots1; tileType <= Red; tileType++ {
+		added[len(tileTypes)] = tileType
+		if CountShanten(added, melds).Min() >= shanten {
+			continue
+		}
+		remaining := 4 - visible[tileType]
+		if remaining < 0 {
+			remaining = 0
+		}
+		ukeire.Tiles = append(ukeire.Tiles, WaitingTile{TileType: tileType, Remaining: remaining})
+		ukeire.Total += remaining
+	}
+	return ukeire
+}
+
+//聴牌なら待ちの形も付ける
+func (maj *Mahjong) Ukeire(player *Player) Ukeire {
+	ukeire := CountUkeire(toTileTypes(player.Tiles), player.Melds(), maj.VisibleTiles(player))
+	if ukeire.Shanten != 0 {
+		return ukeire
+	}
+	for i := range ukeire.Tiles {
+		ukeire.Tiles[i].Waits = maj.waitTypes(player, ukeire.Tiles[i].TileType)
+	}
+	markNobetan(player, ukeire.Tiles)
+	if len(ukeire.Tiles) == 13 && KokushiShanten(toTileTypes(player.Tiles), player.Melds()) == 0 {
+		for i := range ukeire.Tiles {
+			ukeire.Tiles[i].Waits = []WaitType{Juusanmen}
+		}
+	}
+	return ukeire
+}
+
+//聴牌の待ち牌、聴牌でなければnil
+func (maj *Mahjong) Waits(player *Player) []WaitingTile {
+	ukeire := maj.Ukeire(player)
+	if ukeire.Shanten != 0 {
+		return nil
+	}
+	return ukeire.Tiles
+}
+
+//和了形の分解ごとの待ちの形
+func (maj *Mahjong) waitTypes(player *Player, tileType TileType) []WaitType {
+	last := Tile{TileType: tileType}
+	tiles := make([]Tile, len(player.Tiles), len(player.Tiles)+1)
+	copy(tiles, player.Tiles)
+	base := maj.NewWinningHandBase(player, nil, SortTiles(append(tiles, last)))
+	base.LastTile = last
+	found := make(map[WaitType]bool)
+	waits := make([]WaitType, 0)
+	add := func(wait WaitType) {
+		if !found[wait] {
+			found[wait] = true
+			waits = append(waits, wait)
+		}
+	}
+	for _, hand := range base.normalWin() {
+		for _, wait := range hand.waits() {
+			add(wait)
+		}
+	}
+	if base.is7PairsWin() != nil || base.thirteenOrphansWin() != nil {
+		add(Tanki)
+	}
+	return waits
+}
+
+//連続する4枚の両端の単騎は延べ単
+func markNobetan(player *Player, tiles []WaitingTile) {
+	counts := countTileTypes(toTileTypes(player.Tiles))
+	tanki := make(map[TileType]int)
+	for i, tile := range tiles {
+		for _, wait := range tile.Waits {
+			if wait == Tanki {
+				tanki[tile.TileType] = i
+			}
+		}
+	}
+	for low, i := range tanki {
+		high := low + 3
+		j, ok := tanki[high]
+		if !ok || !low.IsSuit() || !low.SameSuit(high) {
+			continue
+		}
+		if counts[low] == 0 || counts[low+1] == 0 || counts[low+2] == 0 || counts[high] == 0 {
+			continue
+		}
+		replaceWait(&tiles[i], Tanki, Nobetan)
+		replaceWait(&tiles[j], Tanki, Nobetan)
+	}
+}
+
+func replaceWait(tile *WaitingTile, from, to WaitType) {
+	for i, wait := range tile.Waits {
+		if wait == from {
+			tile.Waits[i] = to
+		}
+	}
+}
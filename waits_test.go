@@ -0,0 +1,108 @@
+package mahjong
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMahjong_Waits(t *testing.T) {
+	tests := []struct {
+		name      string
+		tileTypes []TileType
+		want      []WaitingTile
+	}{
+		{
+			"三面張",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, South, South,
+				Dots2, Dots3,
+			},
+			[]WaitingTile{
+				{Dots1, []WaitType{Ryanmen}, 3}, {Dots4, []WaitType{Ryanmen}, 3}, {Dots7, []WaitType{Ryanmen}, 4},
+			},
+		},
+		{
+			"双碰",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, South, South,
+				North, North,
+			},
+			[]WaitingTile{{South, []WaitType{Shanpon}, 2}, {North, []WaitType{Shanpon}, 2}},
+		},
+		{
+			"嵌張",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, South, South,
+				Dots7, Dots9,
+			},
+			[]WaitingTile{{Dots8, []WaitType{Kanchan}, 4}},
+		},
+		{
+			"延べ単",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, Bamboo1, Bamboo2,
+				Bamboo3, Bamboo4,
+			},
+			[]WaitingTile{{Bamboo1, []WaitType{Nobetan}, 3}, {Bamboo4, []WaitType{Nobetan}, 3}},
+		},
+		{
+			"七対子",
+			[]TileType{
+				Characters1, Characters1, Characters5, Characters5, Dots2, Dots2, Dots8, Dots8, Bamboo3, Bamboo3, South,
+				South, Red,
+			},
+			[]WaitingTile{{Red, []WaitType{Tanki}, 3}},
+		},
+		{
+			"一向聴",
+			[]TileType{
+				Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, South, North,
+				Dots2, Dots3,
+			},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				maj, _ := newTestMahjong()
+				player := maj.Players.FindField(SouthField)
+				player.Tiles = toSampleTiles(tt.tileTypes)
+				//西家の河に1p
+				maj.Players.FindField(WestField).Discards = []DiscardTile{{Tile: Tile{TileType: Dots1}}}
+				if got := maj.Waits(player); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Waits() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMahjong_Waits_Juusanmen(t *testing.T) {
+	maj, _ := newTestMahjong()
+	player := maj.Players.Now()
+	player.Tiles = toSampleTiles(Yaochu)
+	waits := maj.Waits(player)
+	if len(waits) != 13 {
+		t.Fatalf("Waits() = %v", waits)
+	}
+	for _, wait := range waits {
+		if len(wait.Waits) != 1 || wait.Waits[0] != Juusanmen {
+			t.Errorf("Waits() %v = %v, want 十三面", wait.TileType, wait.Waits)
+		}
+	}
+}
+
+func TestCountUkeire(t *testing.T) {
+	tileTypes := []TileType{
+		Characters1, Characters2, Characters3, Dots4, Dots5, Dots6, Bamboo7, Bamboo8, Bamboo9, East, South, Dots2,
+		Dots3,
+	}
+	var visible [Red + 1]int
+	visible[East] = 3
+	ukeire := CountUkeire(tileTypes, 0, visible)
+	want := []WaitingTile{{Dots1, nil, 4}, {Dots4, nil, 4}, {Dots7, nil, 4}, {East, nil, 1}, {South, nil, 4}}
+	if ukeire.Shanten != 1 || ukeire.Total != 17 || !reflect.DeepEqual(ukeire.Tiles, want) {
+		t.Errorf("CountUkeire() = %+v", ukeire)
+	}
+}